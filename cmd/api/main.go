@@ -14,6 +14,13 @@ func main() {
 		panic(err)
 	}
 	dependencies := config.NewDependencies(ctx, envs)
+	defer dependencies.EventPublisher.Close()
+	defer dependencies.UserEventConsumer.Close()
+	go dependencies.Retention.Start(ctx)
+	go dependencies.OutboxRelay.Start(ctx)
+	go dependencies.WebhookDispatcher.Start(ctx)
+	go dependencies.ChangeStream.Start(ctx)
+	go dependencies.UserEventConsumer.Start(ctx)
 	e := router.SetupRouter(dependencies)
 	err = e.Start(":" + envs.ApiPort)
 	if err != nil {
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"github.com/ADAGroupTcc/ms-channels-api/config"
+	profilesRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	usersRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/users"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/profiles"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+)
+
+// backfill-profiles builds the local user profiles from the users collection, it is run once
+// before relying on the profiles and can be run again to repair them, or to fill the location
+// and categories of profiles projected before they were kept.
+func main() {
+	batchSize := flag.Int64("batch-size", 500, "number of users read at once")
+	flag.Parse()
+
+	ctx := context.Background()
+	envs, err := config.LoadEnvVars()
+	if err != nil {
+		panic(err)
+	}
+	database, err := mongorm.Connect(envs.DBUri, envs.DBName)
+	if err != nil {
+		panic(err)
+	}
+
+	profileService := profiles.New(usersRepository.New(database), profilesRepository.New(database))
+	total, err := profileService.Backfill(ctx, *batchSize)
+	if err != nil {
+		log.Fatalf("backfill stopped after %d profiles: %v", total, err)
+	}
+	log.Printf("backfilled %d profiles", total)
+}
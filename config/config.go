@@ -1,20 +1,89 @@
 package config
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
 	"github.com/joho/godotenv"
 	"github.com/kelseyhightower/envconfig"
 )
 
+// ENVIRONMENT_DEVELOPMENT relaxes the checks that get in the way of running the service locally
+const ENVIRONMENT_DEVELOPMENT = "development"
+
 // Environments define the environment variables
 type Environments struct {
 	ApiPort string `envconfig:"PORT" default:"8081"`
+	// Environment is development when running locally, any other value is treated as production
+	Environment string `envconfig:"ENVIRONMENT" default:"production"`
 
+	// DBUri must point to a replica set, channel writes are transactions
 	DBUri  string `envconfig:"MONGODB_URI"`
 	DBName string `envconfig:"MONGODB_DBNAME"`
 
-	KafkaBrokers     string `envconfig:"KAFKA_BROKERS" default:"localhost"`
+	KafkaBrokers     string `envconfig:"KAFKA_BROKERS"`
 	KafkaTopicOutput string `envconfig:"KAFKA_TOPIC_OUTPUT" default:"output"`
 	KafkaConsumerId  string `envconfig:"KAFKA_CONSUMER_ID" default:"0"`
+	KafkaTopicUsers  string `envconfig:"KAFKA_TOPIC_USERS" default:"users"`
+	// KafkaTopicUsersDeadLetter receives the user events still failing after ConsumerMaxAttempts
+	KafkaTopicUsersDeadLetter string `envconfig:"KAFKA_TOPIC_USERS_DEAD_LETTER" default:"users.dead-letter"`
+	ConsumerMaxAttempts       int    `envconfig:"CONSUMER_MAX_ATTEMPTS" default:"5"`
+
+	// EventsEncoding is how CloudEvents are written to Kafka, structured or binary
+	EventsEncoding string `envconfig:"EVENTS_ENCODING" default:"structured"`
+	EventsSource   string `envconfig:"EVENTS_SOURCE" default:"/ms-channels-api"`
+	// PublicBaseUrl is the URL the service is reachable at, used in the dataschema of the events
+	PublicBaseUrl string `envconfig:"PUBLIC_BASE_URL" default:"http://localhost:8081"`
+
+	// ChangeStreamEnabled publishes the changes made to the channels outside the service
+	ChangeStreamEnabled bool `envconfig:"CHANGE_STREAM_ENABLED" default:"false"`
+
+	// UsersApiUrl is the base URL of the users service, without it users are checked against the local profiles
+	UsersApiUrl              string        `envconfig:"USERS_API_URL"`
+	UsersApiTimeout          time.Duration `envconfig:"USERS_API_TIMEOUT" default:"2s"`
+	UsersApiBreakerThreshold int           `envconfig:"USERS_API_BREAKER_THRESHOLD" default:"5"`
+	UsersApiBreakerCooldown  time.Duration `envconfig:"USERS_API_BREAKER_COOLDOWN" default:"30s"`
+
+	// CursorSecret signs the pagination cursors, it must be shared by every instance outside development
+	CursorSecret string `envconfig:"CURSOR_SECRET"`
+
+	OutboxRelayInterval      time.Duration `envconfig:"OUTBOX_RELAY_INTERVAL" default:"1s"`
+	OutboxBatchSize          int64         `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	OutboxDeliveredRetention time.Duration `envconfig:"OUTBOX_DELIVERED_RETENTION" default:"24h"`
+	// OutboxMaxAttempts is the number of failed deliveries after which an entry is parked as dead
+	OutboxMaxAttempts int `envconfig:"OUTBOX_MAX_ATTEMPTS" default:"10"`
+
+	WebhookDispatchInterval time.Duration `envconfig:"WEBHOOK_DISPATCH_INTERVAL" default:"1s"`
+	WebhookBatchSize        int64         `envconfig:"WEBHOOK_BATCH_SIZE" default:"100"`
+	WebhookTimeout          time.Duration `envconfig:"WEBHOOK_TIMEOUT" default:"10s"`
+	WebhookMaxAttempts      int           `envconfig:"WEBHOOK_MAX_ATTEMPTS" default:"8"`
+	// WebhookDisableAfter is the number of failed attempts in a row that disables a webhook
+	WebhookDisableAfter int `envconfig:"WEBHOOK_DISABLE_AFTER" default:"20"`
+
+	RetentionPurgeInterval time.Duration `envconfig:"RETENTION_PURGE_INTERVAL" default:"1h"`
+
+	RecommendationCategoryWeight  float64 `envconfig:"RECOMMENDATION_CATEGORY_WEIGHT" default:"0.4"`
+	RecommendationProximityWeight float64 `envconfig:"RECOMMENDATION_PROXIMITY_WEIGHT" default:"0.25"`
+	RecommendationCoMembersWeight float64 `envconfig:"RECOMMENDATION_CO_MEMBERS_WEIGHT" default:"0.25"`
+	RecommendationActivityWeight  float64 `envconfig:"RECOMMENDATION_ACTIVITY_WEIGHT" default:"0.1"`
+	// RecommendationCandidatePool caps how many channels are ranked by activity and can be paged through,
+	// once ranked on the other signals
+	RecommendationCandidatePool int64 `envconfig:"RECOMMENDATION_CANDIDATE_POOL" default:"200"`
+
+	ModerationStaffIds   []string `envconfig:"MODERATION_STAFF_IDS"`
+	ReportsFlagThreshold int64    `envconfig:"REPORTS_FLAG_THRESHOLD" default:"5"`
+
+	// DenunciatedMembersPolicy is one of exclude, anonymize or flag
+	DenunciatedMembersPolicy string `envconfig:"DENUNCIATED_MEMBERS_POLICY" default:"flag"`
+
+	// ContentPolicyFile is the path of the JSON rules file, see content-policy.example.json
+	ContentPolicyFile string `envconfig:"CONTENT_POLICY_FILE"`
+}
+
+func (e *Environments) IsDevelopment() bool {
+	return e.Environment == ENVIRONMENT_DEVELOPMENT
 }
 
 // LoadEnvVars load the environment variables
@@ -24,5 +93,11 @@ func LoadEnvVars() (*Environments, error) {
 	if err := envconfig.Process("", c); err != nil {
 		return nil, err
 	}
+	if !domain.IsValidDenunciationPolicy(c.DenunciatedMembersPolicy) {
+		return nil, fmt.Errorf("invalid DENUNCIATED_MEMBERS_POLICY: %s", c.DenunciatedMembersPolicy)
+	}
+	if !events.IsValidEncoding(c.EventsEncoding) {
+		return nil, fmt.Errorf("invalid EVENTS_ENCODING: %s", c.EventsEncoding)
+	}
 	return c, nil
 }
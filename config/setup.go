@@ -2,18 +2,61 @@ package config
 
 import (
 	"context"
+	"crypto/rand"
+	"log"
 
+	"github.com/ADAGroupTcc/ms-channels-api/internal/directory"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	auditHandler "github.com/ADAGroupTcc/ms-channels-api/internal/http/audit"
 	handler "github.com/ADAGroupTcc/ms-channels-api/internal/http/channels"
 	"github.com/ADAGroupTcc/ms-channels-api/internal/http/health"
+	messagesHandler "github.com/ADAGroupTcc/ms-channels-api/internal/http/messages"
+	recommendationsHandler "github.com/ADAGroupTcc/ms-channels-api/internal/http/recommendations"
+	reportsHandler "github.com/ADAGroupTcc/ms-channels-api/internal/http/reports"
+	schemasHandler "github.com/ADAGroupTcc/ms-channels-api/internal/http/schemas"
+	webhooksHandler "github.com/ADAGroupTcc/ms-channels-api/internal/http/webhooks"
+	auditRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/audit"
 	repository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	deliveriesRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/deliveries"
+	inboxRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/inbox"
+	messagesRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/messages"
+	outboxRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	profilesRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	reportsRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/reports"
+	sanctionsRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/sanctions"
+	webhooksRepository "github.com/ADAGroupTcc/ms-channels-api/internal/repositories/webhooks"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/audit"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/changestream"
 	service "github.com/ADAGroupTcc/ms-channels-api/internal/services/channels"
 	healthService "github.com/ADAGroupTcc/ms-channels-api/internal/services/health"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/messages"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/recommendations"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/reports"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/retention"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/userevents"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/webhooks"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/contentpolicy"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/cursor"
 	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
 )
 
 type Dependencies struct {
-	Handler       handler.Handler
-	HealthHandler health.Health
+	Handler               handler.Handler
+	HealthHandler         health.Health
+	RecommendationHandler recommendationsHandler.Handler
+	ReportHandler         reportsHandler.Handler
+	AuditHandler          auditHandler.Handler
+	MessageHandler        messagesHandler.Handler
+	WebhookHandler        webhooksHandler.Handler
+	SchemaHandler         schemasHandler.Handler
+	Retention             retention.Service
+	EventPublisher        events.EventPublisher
+	OutboxRelay           outbox.Relay
+	WebhookDispatcher     webhooks.Dispatcher
+	ChangeStream          changestream.Service
+	UserEventConsumer     events.EventConsumer
 }
 
 func NewDependencies(ctx context.Context, envs *Environments) *Dependencies {
@@ -21,14 +64,126 @@ func NewDependencies(ctx context.Context, envs *Environments) *Dependencies {
 	if err != nil {
 		panic(err)
 	}
+	// every channel write is a transaction along with its outbox events
+	transactions, err := mongorm.SupportsTransactions(ctx, database)
+	if err != nil {
+		panic(err)
+	}
+	if !transactions {
+		panic("MongoDB must run as a replica set, a standalone server does not support transactions")
+	}
 	channelsRepository := repository.New(database)
-	channelService := service.New(channelsRepository)
+	if err := channelsRepository.EnsureIndexes(ctx); err != nil {
+		panic(err)
+	}
+	contentPolicy, err := contentpolicy.Load(envs.ContentPolicyFile)
+	if err != nil {
+		panic(err)
+	}
+	auditService := audit.New(auditRepository.New(database), channelsRepository, envs.ModerationStaffIds)
+	sanctionRepository := sanctionsRepository.New(database)
+	outboxRepository := outboxRepository.New(database)
+	if err := outboxRepository.EnsureIndexes(ctx); err != nil {
+		panic(err)
+	}
+	profileRepository := profilesRepository.New(database)
+	userDirectory := directory.New(directory.Config{
+		BaseUrl:          envs.UsersApiUrl,
+		Timeout:          envs.UsersApiTimeout,
+		BreakerThreshold: envs.UsersApiBreakerThreshold,
+		BreakerCooldown:  envs.UsersApiBreakerCooldown,
+	}, profileRepository)
+	channelService := service.New(channelsRepository, envs.DenunciatedMembersPolicy, contentPolicy, auditService, sanctionRepository, outboxRepository, profileRepository, userDirectory, cursor.NewSigner(cursorSecret(envs.CursorSecret, envs.IsDevelopment())))
 	channelHandler := handler.New(channelService)
 
 	healthService := healthService.New(database)
 	healthHandler := health.New(healthService)
+
+	recommendationService := recommendations.New(channelsRepository, profileRepository, domain.RecommendationWeights{
+		Category:  envs.RecommendationCategoryWeight,
+		Proximity: envs.RecommendationProximityWeight,
+		CoMembers: envs.RecommendationCoMembersWeight,
+		Activity:  envs.RecommendationActivityWeight,
+	}, envs.RecommendationCandidatePool)
+	recommendationHandler := recommendationsHandler.New(recommendationService)
+
+	messageRepository := messagesRepository.New(database)
+	if err := messageRepository.EnsureIndexes(ctx); err != nil {
+		panic(err)
+	}
+
+	reportRepository := reportsRepository.New(database)
+	reportService := reports.New(reportRepository, channelsRepository, messageRepository, outboxRepository, channelService, envs.ModerationStaffIds, envs.ReportsFlagThreshold)
+	reportHandler := reportsHandler.New(reportService)
+
+	auditHandler := auditHandler.New(auditService)
+
+	messageService := messages.New(messageRepository, channelsRepository, sanctionRepository, contentPolicy, outboxRepository)
+	messageHandler := messagesHandler.New(messageService)
+
+	retentionService := retention.New(channelsRepository, messageRepository, envs.RetentionPurgeInterval)
+
+	webhookRepository := webhooksRepository.New(database)
+	deliveryRepository := deliveriesRepository.New(database)
+	if err := deliveryRepository.EnsureIndexes(ctx); err != nil {
+		panic(err)
+	}
+	webhookService := webhooks.New(webhookRepository, deliveryRepository, channelsRepository, envs.IsDevelopment())
+	webhookHandler := webhooksHandler.New(webhookService)
+	schemaRegistry, err := events.NewSchemaRegistry(envs.PublicBaseUrl)
+	if err != nil {
+		panic(err)
+	}
+	schemaHandler := schemasHandler.New(schemaRegistry)
+	eventEncoder := events.NewCloudEventEncoder(envs.EventsSource, schemaRegistry)
+
+	webhookDispatcher := webhooks.NewDispatcher(webhookRepository, deliveryRepository, eventEncoder, webhooks.Config{
+		Interval:             envs.WebhookDispatchInterval,
+		BatchSize:            envs.WebhookBatchSize,
+		Timeout:              envs.WebhookTimeout,
+		MaxAttempts:          envs.WebhookMaxAttempts,
+		DisableAfter:         envs.WebhookDisableAfter,
+		AllowPrivateNetworks: envs.IsDevelopment(),
+	})
+
+	eventPublisher := events.NewPublisher(envs.KafkaBrokers, envs.KafkaTopicOutput, eventEncoder, envs.EventsEncoding)
+	servicePublisher := events.NewMultiPublisher(eventPublisher, webhookDispatcher)
+	outboxRelay := outbox.New(outboxRepository, servicePublisher, envs.OutboxRelayInterval, envs.OutboxBatchSize, envs.OutboxDeliveredRetention, envs.OutboxMaxAttempts)
+	changeStream := changestream.New(channelsRepository, servicePublisher, envs.ChangeStreamEnabled)
+	userEventService := userevents.New(inboxRepository.New(database), profileRepository, channelService)
+	userEventConsumer := events.NewConsumer(envs.KafkaBrokers, envs.KafkaTopicUsers, envs.KafkaConsumerId, userEventService.Handle, envs.KafkaTopicUsersDeadLetter, envs.ConsumerMaxAttempts)
 	return &Dependencies{
 		channelHandler,
 		healthHandler,
+		recommendationHandler,
+		reportHandler,
+		auditHandler,
+		messageHandler,
+		webhookHandler,
+		schemaHandler,
+		retentionService,
+		eventPublisher,
+		outboxRelay,
+		webhookDispatcher,
+		changeStream,
+		userEventConsumer,
+	}
+}
+
+// cursorSecret falls back to a random secret when CURSOR_SECRET is not set, cursors then only work
+// on the instance that issued them and until it restarts, which production must not rely on.
+func cursorSecret(secret string, development bool) []byte {
+	if secret != "" {
+		return []byte(secret)
+	}
+	if development {
+		log.Println("CURSOR_SECRET is not set, using a random secret")
+	} else {
+		log.Println("WARNING: CURSOR_SECRET is not set, using a random secret. Pagination cursors break across instances and restarts, set CURSOR_SECRET to the same value on every instance")
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		panic(err)
 	}
+	return random
 }
@@ -1,10 +1,17 @@
 package exceptions
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 type Error struct {
 	Err        error
 	TraceError error
+	// RetryAfter tells the client how long to wait before retrying, when relevant
+	RetryAfter time.Duration
+	// UnknownIds lists the ids the request referenced that do not exist, when relevant
+	UnknownIds []string
 }
 
 func New(err error, traceError error) *Error {
@@ -14,6 +21,20 @@ func New(err error, traceError error) *Error {
 	}
 }
 
+func NewWithRetryAfter(err error, retryAfter time.Duration) *Error {
+	return &Error{
+		Err:        err,
+		RetryAfter: retryAfter,
+	}
+}
+
+func NewWithUnknownIds(err error, unknownIds []string) *Error {
+	return &Error{
+		Err:        err,
+		UnknownIds: unknownIds,
+	}
+}
+
 func (e *Error) Error() string {
 	return fmt.Sprintf("%s: %s", e.Err, e.TraceError)
 }
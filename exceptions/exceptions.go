@@ -9,16 +9,62 @@ const prefix = "channels-api"
 var (
 
 	// Errors related to request validation
-	ErrInvalidPayload       = fmt.Errorf("%s: invalid payload", prefix)
-	ErrChannelAlreadyExists = fmt.Errorf("%s: channel already exists", prefix)
-	ErrInvalidNameField     = fmt.Errorf("%s: invalid name field", prefix)
-	ErrInvalidMembersField  = fmt.Errorf("%s: invalid members field", prefix)
-	ErrInvalidAdminsField   = fmt.Errorf("%s: invalid admins field", prefix)
-	ErrInvalidID            = fmt.Errorf("%s: invalid ID", prefix)
-	ErrInvalidUserIdSent    = fmt.Errorf("%s: invalid user ID sent", prefix)
-	ErrNoFieldsToUpdate     = fmt.Errorf("%s: no fields to update", prefix)
-	ErrHeaderUserIdIsReq    = fmt.Errorf("%s: header user ID is required", prefix)
+	ErrInvalidPayload            = fmt.Errorf("%s: invalid payload", prefix)
+	ErrChannelAlreadyExists      = fmt.Errorf("%s: channel already exists", prefix)
+	ErrInvalidNameField          = fmt.Errorf("%s: invalid name field", prefix)
+	ErrInvalidMembersField       = fmt.Errorf("%s: invalid members field", prefix)
+	ErrInvalidAdminsField        = fmt.Errorf("%s: invalid admins field", prefix)
+	ErrInvalidID                 = fmt.Errorf("%s: invalid ID", prefix)
+	ErrInvalidUserIdSent         = fmt.Errorf("%s: invalid user ID sent", prefix)
+	ErrNoFieldsToUpdate          = fmt.Errorf("%s: no fields to update", prefix)
+	ErrHeaderUserIdIsReq         = fmt.Errorf("%s: header user ID is required", prefix)
+	ErrInvalidRetentionField     = fmt.Errorf("%s: invalid retention_days field", prefix)
+	ErrInvalidVisibilityField    = fmt.Errorf("%s: invalid visibility field", prefix)
+	ErrInvalidCategoriesField    = fmt.Errorf("%s: invalid categories field", prefix)
+	ErrInvalidLocationField      = fmt.Errorf("%s: invalid location field", prefix)
+	ErrLocationIsReq             = fmt.Errorf("%s: location is required", prefix)
+	ErrInvalidReportTarget       = fmt.Errorf("%s: invalid report target", prefix)
+	ErrInvalidReasonField        = fmt.Errorf("%s: invalid reason field", prefix)
+	ErrInvalidReportAction       = fmt.Errorf("%s: invalid report action", prefix)
+	ErrInvalidReportStatus       = fmt.Errorf("%s: invalid report status", prefix)
+	ErrReportAlreadyExists       = fmt.Errorf("%s: report already exists", prefix)
+	ErrReportAlreadyResolved     = fmt.Errorf("%s: report already resolved", prefix)
+	ErrInvalidNameContent        = fmt.Errorf("%s: name violates the content policy", prefix)
+	ErrInvalidDescriptionContent = fmt.Errorf("%s: description violates the content policy", prefix)
+	ErrInvalidAuditAction        = fmt.Errorf("%s: invalid audit action", prefix)
+	ErrInvalidContentField       = fmt.Errorf("%s: invalid content field", prefix)
+	ErrInvalidMessageContent     = fmt.Errorf("%s: message violates the content policy", prefix)
+	ErrInvalidSlowModeField      = fmt.Errorf("%s: invalid slow_mode_seconds field", prefix)
+	ErrInvalidRateLimitField     = fmt.Errorf("%s: invalid messages_per_minute field", prefix)
+	ErrInvalidUnlockAtField      = fmt.Errorf("%s: invalid unlock_at field", prefix)
+	ErrInvalidUntilField         = fmt.Errorf("%s: invalid until field", prefix)
+	ErrInvalidMemberId           = fmt.Errorf("%s: invalid member ID", prefix)
+	ErrCannotSanctionMember      = fmt.Errorf("%s: member cannot be sanctioned", prefix)
+	ErrInvalidUrlField           = fmt.Errorf("%s: invalid url field", prefix)
+	ErrInvalidSecretField        = fmt.Errorf("%s: invalid secret field", prefix)
+	ErrInvalidEventTypesField    = fmt.Errorf("%s: invalid event_types field", prefix)
+	ErrInvalidDeliveryStatus     = fmt.Errorf("%s: invalid delivery status", prefix)
+	ErrUnknownUserIds            = fmt.Errorf("%s: unknown user IDs sent", prefix)
+	ErrInvalidCursor             = fmt.Errorf("%s: invalid cursor", prefix)
+	ErrInvalidSortField          = fmt.Errorf("%s: invalid sort field", prefix)
+	ErrInvalidFieldsParam        = fmt.Errorf("%s: invalid fields param", prefix)
+	// Errors related to permissions
+	ErrUserIsNotStaff  = fmt.Errorf("%s: user is not a moderation staff member", prefix)
+	ErrUserIsNotAdmin  = fmt.Errorf("%s: user is not an admin of the channel", prefix)
+	ErrUserIsNotMember = fmt.Errorf("%s: user is not a member of the channel", prefix)
+	ErrMemberTimedOut  = fmt.Errorf("%s: member is timed out in the channel", prefix)
+	// Errors related to posting limits
+	ErrSlowModeActive     = fmt.Errorf("%s: slow mode is active, wait before posting again", prefix)
+	ErrChannelRateLimited = fmt.Errorf("%s: channel message rate limit reached", prefix)
+	// Errors related to channel state
+	ErrChannelLocked = fmt.Errorf("%s: channel is locked", prefix)
 	// Database related errors
 	ErrChannelNotFound = fmt.Errorf("%s: channel not found", prefix)
+	ErrUserNotFound    = fmt.Errorf("%s: user not found", prefix)
+	ErrReportNotFound  = fmt.Errorf("%s: report not found", prefix)
+	ErrWebhookNotFound = fmt.Errorf("%s: webhook not found", prefix)
+	ErrSchemaNotFound  = fmt.Errorf("%s: schema not found", prefix)
 	ErrDatabaseFailure = fmt.Errorf("%s: database failure", prefix)
+	// Errors related to other services
+	ErrUserDirectoryUnavailable = fmt.Errorf("%s: users directory unavailable", prefix)
 )
@@ -2,12 +2,15 @@ package exceptions
 
 import (
 	"fmt"
+	"math"
 	"net/http"
 )
 
 type ErrorResponse struct {
-	Code    int    `json:"code,omitempty"`
-	Message string `json:"message"`
+	Code       int      `json:"code,omitempty"`
+	Message    string   `json:"message"`
+	RetryAfter int      `json:"retry_after,omitempty"`
+	UnknownIds []string `json:"unknown_ids,omitempty"`
 }
 
 func HandleExceptions(err error) ErrorResponse {
@@ -26,7 +29,7 @@ func HandleExceptions(err error) ErrorResponse {
 	}
 
 	switch customErr.Err {
-	case ErrChannelNotFound:
+	case ErrChannelNotFound, ErrUserNotFound, ErrReportNotFound, ErrWebhookNotFound, ErrSchemaNotFound:
 		return ErrorResponse{
 			Code:    http.StatusNotFound,
 			Message: customErr.Err.Error(),
@@ -43,11 +46,73 @@ func HandleExceptions(err error) ErrorResponse {
 		ErrInvalidMembersField,
 		ErrInvalidAdminsField,
 		ErrHeaderUserIdIsReq,
-		ErrInvalidUserIdSent:
+		ErrInvalidUserIdSent,
+		ErrInvalidRetentionField,
+		ErrInvalidVisibilityField,
+		ErrInvalidCategoriesField,
+		ErrInvalidLocationField,
+		ErrLocationIsReq,
+		ErrInvalidReportTarget,
+		ErrInvalidReasonField,
+		ErrInvalidReportAction,
+		ErrInvalidReportStatus,
+		ErrReportAlreadyExists,
+		ErrReportAlreadyResolved,
+		ErrInvalidNameContent,
+		ErrInvalidDescriptionContent,
+		ErrInvalidAuditAction,
+		ErrInvalidContentField,
+		ErrInvalidMessageContent,
+		ErrInvalidSlowModeField,
+		ErrInvalidRateLimitField,
+		ErrInvalidUnlockAtField,
+		ErrInvalidUntilField,
+		ErrInvalidMemberId,
+		ErrCannotSanctionMember,
+		ErrInvalidUrlField,
+		ErrInvalidSecretField,
+		ErrInvalidEventTypesField,
+		ErrInvalidDeliveryStatus,
+		ErrInvalidCursor,
+		ErrInvalidSortField,
+		ErrInvalidFieldsParam:
 		return ErrorResponse{
 			Code:    http.StatusBadRequest,
 			Message: customErr.Err.Error(),
 		}
+	case ErrUnknownUserIds:
+		return ErrorResponse{
+			Code:       http.StatusBadRequest,
+			Message:    customErr.Err.Error(),
+			UnknownIds: customErr.UnknownIds,
+		}
+	case ErrChannelLocked:
+		return ErrorResponse{
+			Code:    http.StatusLocked,
+			Message: customErr.Err.Error(),
+		}
+	case ErrSlowModeActive, ErrChannelRateLimited:
+		return ErrorResponse{
+			Code:       http.StatusTooManyRequests,
+			Message:    customErr.Err.Error(),
+			RetryAfter: int(math.Ceil(customErr.RetryAfter.Seconds())),
+		}
+	case ErrMemberTimedOut:
+		return ErrorResponse{
+			Code:       http.StatusForbidden,
+			Message:    customErr.Err.Error(),
+			RetryAfter: int(math.Ceil(customErr.RetryAfter.Seconds())),
+		}
+	case ErrUserIsNotStaff, ErrUserIsNotAdmin, ErrUserIsNotMember:
+		return ErrorResponse{
+			Code:    http.StatusForbidden,
+			Message: customErr.Err.Error(),
+		}
+	case ErrUserDirectoryUnavailable:
+		return ErrorResponse{
+			Code:    http.StatusServiceUnavailable,
+			Message: customErr.Err.Error(),
+		}
 	case ErrDatabaseFailure:
 		return ErrorResponse{
 			Code:    http.StatusInternalServerError,
@@ -0,0 +1,64 @@
+package directory
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// UserDirectory tells which of the given users do not exist.
+type UserDirectory interface {
+	Unknown(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+}
+
+type Config struct {
+	BaseUrl          string
+	Timeout          time.Duration
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+}
+
+// New asks the users API when a base URL is configured, falling back to the local profiles
+// while it fails or its circuit breaker is open.
+func New(config Config, profileRepository profiles.Repository) UserDirectory {
+	mongoDirectory := NewMongoUserDirectory(profileRepository)
+	if config.BaseUrl == "" {
+		return mongoDirectory
+	}
+	return &FallbackUserDirectory{
+		primary:  NewHTTPUserDirectory(config),
+		fallback: mongoDirectory,
+	}
+}
+
+type FallbackUserDirectory struct {
+	primary  UserDirectory
+	fallback UserDirectory
+}
+
+func (d *FallbackUserDirectory) Unknown(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	unknown, err := d.primary.Unknown(ctx, ids)
+	if err == nil {
+		return unknown, nil
+	}
+	log.Println("users API unavailable, using the local profiles:", err)
+	return d.fallback.Unknown(ctx, ids)
+}
+
+// difference returns the ids that are not in found.
+func difference(ids []primitive.ObjectID, found []primitive.ObjectID) []primitive.ObjectID {
+	present := make(map[primitive.ObjectID]bool, len(found))
+	for _, id := range found {
+		present[id] = true
+	}
+	unknown := make([]primitive.ObjectID, 0)
+	for _, id := range ids {
+		if !present[id] {
+			unknown = append(unknown, id)
+		}
+	}
+	return unknown
+}
@@ -0,0 +1,95 @@
+package directory
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/circuitbreaker"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// USERS_PER_REQUEST bounds the ids sent at once to keep the URLs short.
+const USERS_PER_REQUEST = 100
+
+// HTTPUserDirectory asks the users API through GET /v1/users?user_ids=..., which answers
+// {"users": [{"id": ...}]} with the users that exist.
+type HTTPUserDirectory struct {
+	baseUrl string
+	client  *http.Client
+	breaker *circuitbreaker.Breaker
+}
+
+type usersResponse struct {
+	Users []struct {
+		ID primitive.ObjectID `json:"id"`
+	} `json:"users"`
+}
+
+func NewHTTPUserDirectory(config Config) UserDirectory {
+	return &HTTPUserDirectory{
+		baseUrl: strings.TrimSuffix(config.BaseUrl, "/"),
+		client:  &http.Client{Timeout: config.Timeout},
+		breaker: circuitbreaker.New(config.BreakerThreshold, config.BreakerCooldown),
+	}
+}
+
+func (d *HTTPUserDirectory) Unknown(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	found := make([]primitive.ObjectID, 0, len(ids))
+	for start := 0; start < len(ids); start += USERS_PER_REQUEST {
+		end := start + USERS_PER_REQUEST
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		var chunk []primitive.ObjectID
+		err := d.breaker.Do(func() error {
+			var err error
+			chunk, err = d.fetch(ctx, ids[start:end])
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, chunk...)
+	}
+	return difference(ids, found), nil
+}
+
+func (d *HTTPUserDirectory) fetch(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	hexIds := make([]string, 0, len(ids))
+	for _, id := range ids {
+		hexIds = append(hexIds, id.Hex())
+	}
+	query := url.Values{}
+	query.Set("user_ids", strings.Join(hexIds, ","))
+	query.Set("limit", strconv.Itoa(len(ids)))
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, d.baseUrl+"/v1/users?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := d.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("users API answered %d", response.StatusCode)
+	}
+
+	var body usersResponse
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	found := make([]primitive.ObjectID, 0, len(body.Users))
+	for _, user := range body.Users {
+		found = append(found, user.ID)
+	}
+	return found, nil
+}
@@ -0,0 +1,25 @@
+package directory
+
+import (
+	"context"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MongoUserDirectory looks the users up in the local profiles, which may lag behind the users service.
+type MongoUserDirectory struct {
+	profileRepository profiles.Repository
+}
+
+func NewMongoUserDirectory(profileRepository profiles.Repository) UserDirectory {
+	return &MongoUserDirectory{profileRepository}
+}
+
+func (d *MongoUserDirectory) Unknown(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	found, err := d.profileRepository.ListExistingIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	return difference(ids, found), nil
+}
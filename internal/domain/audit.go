@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"reflect"
+
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AUDIT_ACTION_CREATED            = "created"
+	AUDIT_ACTION_UPDATED            = "updated"
+	AUDIT_ACTION_DELETED            = "deleted"
+	AUDIT_ACTION_MEMBERSHIP_CHANGED = "membership_changed"
+	AUDIT_ACTION_MEMBER_TIMED_OUT   = "member_timed_out"
+	AUDIT_ACTION_MEMBER_KICKED      = "member_kicked"
+)
+
+type FieldChange struct {
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+type AuditEvent struct {
+	mongorm.Model `bson:",inline"`
+	ChannelId     primitive.ObjectID     `json:"channel_id" bson:"channel_id"`
+	ActorId       *primitive.ObjectID    `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	Action        string                 `json:"action" bson:"action"`
+	Changes       map[string]FieldChange `json:"changes" bson:"changes"`
+	TargetId      *primitive.ObjectID    `json:"target_id,omitempty" bson:"target_id,omitempty"`
+	Reason        string                 `json:"reason,omitempty" bson:"reason,omitempty"`
+}
+
+// AuditedAdmins returns the admins of the channel as the event left them, a deletion keeps the
+// admins the channel had. It is false when the event does not touch the admins.
+func (e *AuditEvent) AuditedAdmins() ([]primitive.ObjectID, bool) {
+	change, ok := e.Changes["admins"]
+	if !ok {
+		return nil, false
+	}
+	value := change.After
+	if e.Action == AUDIT_ACTION_DELETED {
+		value = change.Before
+	}
+
+	admins := make([]primitive.ObjectID, 0)
+	switch value := value.(type) {
+	case []primitive.ObjectID:
+		admins = append(admins, value...)
+	case primitive.A:
+		for _, admin := range value {
+			if id, ok := admin.(primitive.ObjectID); ok {
+				admins = append(admins, id)
+			}
+		}
+	}
+	return admins, true
+}
+
+type AuditResponse struct {
+	Events   []*AuditEvent `json:"events"`
+	NextPage int64         `json:"next_page,omitempty"`
+}
+
+func IsValidAuditAction(action string) bool {
+	switch action {
+	case AUDIT_ACTION_CREATED,
+		AUDIT_ACTION_UPDATED,
+		AUDIT_ACTION_DELETED,
+		AUDIT_ACTION_MEMBERSHIP_CHANGED,
+		AUDIT_ACTION_MEMBER_TIMED_OUT,
+		AUDIT_ACTION_MEMBER_KICKED:
+		return true
+	default:
+		return false
+	}
+}
+
+// auditedFields maps the audited bson field names to their values in the channel.
+func (c *Channel) auditedFields() map[string]interface{} {
+	return map[string]interface{}{
+		"name":                c.Name,
+		"description":         c.Description,
+		"members":             c.Members,
+		"admins":              c.Admins,
+		"retention_days":      c.RetentionDays,
+		"categories":          c.Categories,
+		"visibility":          c.Visibility,
+		"location":            c.Location,
+		"flagged":             c.Flagged,
+		"flag_reasons":        c.FlagReasons,
+		"slow_mode_seconds":   c.SlowModeSeconds,
+		"messages_per_minute": c.MessagesPerMinute,
+		"locked":              c.Locked,
+		"lock":                c.Lock,
+	}
+}
+
+// DiffChannels returns the audited fields that differ between before and after, a nil side
+// stands for a channel that does not exist yet or anymore.
+func DiffChannels(before *Channel, after *Channel) map[string]FieldChange {
+	var beforeFields, afterFields map[string]interface{}
+	if before != nil {
+		beforeFields = before.auditedFields()
+	}
+	if after != nil {
+		afterFields = after.auditedFields()
+	}
+
+	changes := make(map[string]FieldChange)
+	for field := range mergeKeys(beforeFields, afterFields) {
+		if !reflect.DeepEqual(beforeFields[field], afterFields[field]) {
+			changes[field] = FieldChange{Before: beforeFields[field], After: afterFields[field]}
+		}
+	}
+	return changes
+}
+
+// IsMembershipChange tells whether the change touches members or admins.
+func IsMembershipChange(field string) bool {
+	return field == "members" || field == "admins"
+}
+
+func mergeKeys(maps ...map[string]interface{}) map[string]struct{} {
+	keys := make(map[string]struct{})
+	for _, m := range maps {
+		for key := range m {
+			keys[key] = struct{}{}
+		}
+	}
+	return keys
+}
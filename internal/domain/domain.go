@@ -1,6 +1,10 @@
 package domain
 
 import (
+	"math"
+	"slices"
+	"time"
+
 	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
 	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
 	"go.mongodb.org/mongo-driver/bson"
@@ -9,7 +13,19 @@ import (
 
 type ChannelResponse struct {
 	Channels ChannelResponseGeneral `json:"channels"`
-	NextPage int64                  `json:"next_page,omitempty"`
+	Limit    int64                  `json:"limit"`
+	// HasMore tells whether a next page follows in the reading order, HasPrev whether one precedes
+	HasMore    bool   `json:"has_more"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	// Total is only counted when asked for with include_total
+	Total *int64 `json:"total,omitempty"`
+	// Deprecated: NextPage is kept for the clients paging by number, use NextCursor instead
+	NextPage int64 `json:"next_page,omitempty"`
+	// PoolLimitReached is set on the last page of the recommendations when more channels matched
+	// than the ranking pool holds, the ones left out of it are never recommended
+	PoolLimitReached bool `json:"pool_limit_reached,omitempty"`
 }
 
 type User struct {
@@ -25,27 +41,263 @@ type User struct {
 	IsDenunciated bool                 `json:"is_denunciated" bson:"is_denunciated"`
 }
 
+type MembersResponse struct {
+	Members []*UserProfile `json:"members"`
+}
+
 type ChannelResponseGeneral interface{}
 
 type Channel struct {
-	mongorm.Model `bson:",inline"`
-	Name          string               `json:"name" bson:"name"`
-	Description   string               `json:"description" bson:"description"`
-	Members       []primitive.ObjectID `json:"members" bson:"members"`
-	Admins        []primitive.ObjectID `json:"admins" bson:"admins"`
+	mongorm.Model     `bson:",inline"`
+	Name              string               `json:"name" bson:"name"`
+	Description       string               `json:"description" bson:"description"`
+	Members           []primitive.ObjectID `json:"members" bson:"members"`
+	Admins            []primitive.ObjectID `json:"admins" bson:"admins"`
+	MemberCount       int                  `json:"member_count" bson:"member_count"`
+	RetentionDays     int                  `json:"retention_days" bson:"retention_days"`
+	Categories        []primitive.ObjectID `json:"categories" bson:"categories"`
+	Visibility        string               `json:"visibility" bson:"visibility"`
+	Location          *GeoPoint            `json:"location,omitempty" bson:"location,omitempty"`
+	Score             float64              `json:"score,omitempty" bson:"score,omitempty"`
+	Flagged           bool                 `json:"flagged" bson:"flagged"`
+	FlagReasons       []string             `json:"flag_reasons,omitempty" bson:"flag_reasons,omitempty"`
+	SlowModeSeconds   int                  `json:"slow_mode_seconds" bson:"slow_mode_seconds"`
+	MessagesPerMinute int                  `json:"messages_per_minute" bson:"messages_per_minute"`
+	Locked            bool                 `json:"locked" bson:"locked"`
+	Lock              *ChannelLock         `json:"lock,omitempty" bson:"lock,omitempty"`
+}
+
+type ChannelLock struct {
+	Reason   string             `json:"reason" bson:"reason"`
+	LockedBy primitive.ObjectID `json:"locked_by" bson:"locked_by"`
+	LockedAt time.Time          `json:"locked_at" bson:"locked_at"`
+	UnlockAt *time.Time         `json:"unlock_at,omitempty" bson:"unlock_at,omitempty"`
+}
+
+type LockRequest struct {
+	Reason   string     `json:"reason"`
+	UnlockAt *time.Time `json:"unlock_at"`
+}
+
+func (r *LockRequest) Validate(now time.Time) error {
+	if len(r.Reason) < REASON_MINIMUM {
+		return exceptions.New(exceptions.ErrInvalidReasonField, nil)
+	}
+	if r.UnlockAt != nil && !r.UnlockAt.After(now) {
+		return exceptions.New(exceptions.ErrInvalidUnlockAtField, nil)
+	}
+	return nil
+}
+
+func (r *LockRequest) ToChannelLock(lockedBy primitive.ObjectID, now time.Time) *ChannelLock {
+	return &ChannelLock{
+		Reason:   r.Reason,
+		LockedBy: lockedBy,
+		LockedAt: now,
+		UnlockAt: r.UnlockAt,
+	}
+}
+
+// GeoPoint is a GeoJSON point, coordinates are stored as [longitude, latitude].
+type GeoPoint struct {
+	Type        string    `json:"type" bson:"type"`
+	Coordinates []float64 `json:"coordinates" bson:"coordinates"`
+}
+
+func NewGeoPoint(longitude float64, latitude float64) *GeoPoint {
+	return &GeoPoint{
+		Type:        "Point",
+		Coordinates: []float64{longitude, latitude},
+	}
+}
+
+const EARTH_RADIUS_METERS = 6371000
+
+// DistanceTo returns the great-circle distance in meters between two points.
+func (p *GeoPoint) DistanceTo(other *GeoPoint) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	lng1, lat1 := toRadians(p.Coordinates[0]), toRadians(p.Coordinates[1])
+	lng2, lat2 := toRadians(other.Coordinates[0]), toRadians(other.Coordinates[1])
+
+	a := math.Pow(math.Sin((lat2-lat1)/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin((lng2-lng1)/2), 2)
+	return 2 * EARTH_RADIUS_METERS * math.Asin(math.Sqrt(a))
+}
+
+func ValidateCoordinates(longitude float64, latitude float64) error {
+	if longitude < -180 || longitude > 180 || latitude < -90 || latitude > 90 {
+		return exceptions.New(exceptions.ErrInvalidLocationField, nil)
+	}
+	return nil
+}
+
+// ParseLocation converts a [longitude, latitude] pair into a GeoPoint.
+func ParseLocation(location []float64) (*GeoPoint, error) {
+	if len(location) != 2 {
+		return nil, exceptions.New(exceptions.ErrInvalidLocationField, nil)
+	}
+	if err := ValidateCoordinates(location[0], location[1]); err != nil {
+		return nil, err
+	}
+	return NewGeoPoint(location[0], location[1]), nil
+}
+
+// RetentionCutoff returns the instant before which messages of the channel must be purged.
+// Channels with no retention keep their messages forever and return false.
+func (c *Channel) RetentionCutoff(now time.Time) (time.Time, bool) {
+	if c.RetentionDays <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -c.RetentionDays), true
+}
+
+// IsLocked tells whether the channel is read-only at the given instant, locks past their
+// unlock time are considered released even before being cleared from the database.
+func (c *Channel) IsLocked(now time.Time) bool {
+	if !c.Locked {
+		return false
+	}
+	return c.Lock == nil || c.Lock.UnlockAt == nil || now.Before(*c.Lock.UnlockAt)
+}
+
+// RefreshLock clears an expired lock so responses show the effective state.
+func (c *Channel) RefreshLock(now time.Time) {
+	if c.Locked && !c.IsLocked(now) {
+		c.Locked = false
+		c.Lock = nil
+	}
+}
+
+// WithFlagReason returns the flag reasons of the channel once reason is added or removed, and whether
+// the channel stays flagged. Channels flagged before the reasons were recorded have no reasons, their
+// flag is kept as is since its source is unknown.
+func (c *Channel) WithFlagReason(reason string, flagged bool) ([]string, bool) {
+	if c.Flagged && len(c.FlagReasons) == 0 {
+		return c.FlagReasons, true
+	}
+	reasons := make([]string, 0, len(c.FlagReasons)+1)
+	for _, existing := range c.FlagReasons {
+		if existing != reason {
+			reasons = append(reasons, existing)
+		}
+	}
+	if flagged {
+		reasons = append(reasons, reason)
+	}
+	return reasons, len(reasons) > 0
+}
+
+type DiscoveredChannel struct {
+	Channel         `bson:",inline"`
+	CategoryOverlap int `json:"category_overlap" bson:"category_overlap"`
+}
+
+type NearbyChannel struct {
+	Channel  `bson:",inline"`
+	Distance float64 `json:"distance" bson:"distance"`
+}
+
+type RecommendedChannel struct {
+	Channel             `bson:",inline"`
+	CategoryOverlap     int        `json:"category_overlap" bson:"category_overlap"`
+	CoMemberCount       int        `json:"co_member_count" bson:"co_member_count"`
+	Distance            *float64   `json:"distance,omitempty" bson:"distance"`
+	LastMessageAt       *time.Time `json:"last_message_at,omitempty" bson:"last_message_at"`
+	RecommendationScore float64    `json:"recommendation_score" bson:"recommendation_score"`
+	Reasons             []string   `json:"reasons" bson:"-"`
+}
+
+const (
+	// distance in meters at which the proximity score drops to half
+	PROXIMITY_HALF_DISTANCE = 5000
+	// days without messages at which the activity score drops to half
+	ACTIVITY_HALF_LIFE_DAYS = 7
+	// distance in meters within which channels are recommended for their location alone
+	RECOMMENDATION_RADIUS_METERS = 50000
+)
+
+// RecommendationWeights define how much each signal contributes to the recommendation score.
+type RecommendationWeights struct {
+	Category  float64
+	Proximity float64
+	CoMembers float64
+	Activity  float64
+}
+
+// RecommendationQuery describes the user channels are recommended to. Pool is the number of
+// channels kept once ranked on categories, proximity and co-members, the ones reordered by
+// activity and paged through, so recommendations can only be paged that deep.
+type RecommendationQuery struct {
+	UserId     primitive.ObjectID
+	Categories []primitive.ObjectID
+	CoMembers  []primitive.ObjectID
+	Location   *GeoPoint
+	Weights    RecommendationWeights
+	Pool       int64
+	Now        time.Time
+	Limit      int64
+	Offset     int64
 }
 
 type ChannelWithMembers struct {
-	Channel `bson:",inline"`
-	Members []*User `json:"members" bson:"members"`
-	Admins  []*User `json:"admins" bson:"admins"`
+	Channel      `bson:",inline"`
+	Members      []*UserProfile `json:"members" bson:"members"`
+	MembersTotal int64          `json:"members_total" bson:"members_total"`
+	Admins       []*UserProfile `json:"admins" bson:"admins"`
+	Sanctions    []*Sanction    `json:"sanctions,omitempty" bson:"-"`
+}
+
+// ApplyDenunciationPolicy hides, anonymizes or keeps flagged the denunciated users of the expansion.
+func (c *ChannelWithMembers) ApplyDenunciationPolicy(policy string) {
+	c.Members = applyDenunciationPolicy(c.Members, policy)
+	c.Admins = applyDenunciationPolicy(c.Admins, policy)
+}
+
+func (c *ChannelWithMembers) HasAdmin(userId primitive.ObjectID) bool {
+	for _, admin := range c.Admins {
+		if admin.ID == userId {
+			return true
+		}
+	}
+	return false
+}
+
+func applyDenunciationPolicy(users []*UserProfile, policy string) []*UserProfile {
+	switch policy {
+	case DENUNCIATION_POLICY_EXCLUDE:
+		filtered := make([]*UserProfile, 0, len(users))
+		for _, user := range users {
+			if !user.IsDenunciated {
+				filtered = append(filtered, user)
+			}
+		}
+		return filtered
+	case DENUNCIATION_POLICY_ANONYMIZE:
+		for _, user := range users {
+			if user.IsDenunciated {
+				user.Anonymize()
+			}
+		}
+		return users
+	default:
+		return users
+	}
+}
+
+func IsValidDenunciationPolicy(policy string) bool {
+	return policy == DENUNCIATION_POLICY_EXCLUDE || policy == DENUNCIATION_POLICY_ANONYMIZE || policy == DENUNCIATION_POLICY_FLAG
 }
 
 type ChannelRequest struct {
-	Name        string   `json:"name"`
-	Description string   `json:"description"`
-	Members     []string `json:"members"`
-	Admins      []string `json:"admins"`
+	Name              string    `json:"name"`
+	Description       string    `json:"description"`
+	Members           []string  `json:"members"`
+	Admins            []string  `json:"admins"`
+	RetentionDays     int       `json:"retention_days"`
+	Categories        []string  `json:"categories"`
+	Visibility        string    `json:"visibility"`
+	Location          []float64 `json:"location"`
+	SlowModeSeconds   int       `json:"slow_mode_seconds"`
+	MessagesPerMinute int       `json:"messages_per_minute"`
 }
 
 func (r *ChannelRequest) Validate() error {
@@ -58,6 +310,26 @@ func (r *ChannelRequest) Validate() error {
 	if r.Admins == nil || len(r.Admins) < ADMINS_MINIMUM {
 		return exceptions.New(exceptions.ErrInvalidAdminsField, nil)
 	}
+	if r.RetentionDays < 0 {
+		return exceptions.New(exceptions.ErrInvalidRetentionField, nil)
+	}
+	if r.Visibility != "" && !IsValidVisibility(r.Visibility) {
+		return exceptions.New(exceptions.ErrInvalidVisibilityField, nil)
+	}
+	if _, err := ParseCategoryIds(r.Categories); err != nil {
+		return err
+	}
+	if r.Location != nil {
+		if _, err := ParseLocation(r.Location); err != nil {
+			return err
+		}
+	}
+	if r.SlowModeSeconds < 0 {
+		return exceptions.New(exceptions.ErrInvalidSlowModeField, nil)
+	}
+	if r.MessagesPerMinute < 0 {
+		return exceptions.New(exceptions.ErrInvalidRateLimitField, nil)
+	}
 
 	err := r.ValidateMembersAndAdmins()
 	if err != nil {
@@ -89,6 +361,56 @@ func (r *ChannelRequest) ValidateMembersAndAdmins() error {
 	return err
 }
 
+func (c *Channel) IsAdmin(userId primitive.ObjectID) bool {
+	for _, admin := range c.Admins {
+		if admin == userId {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutMember returns the members and admins of the channel without the given user.
+func (c *Channel) WithoutMember(userId primitive.ObjectID) ([]primitive.ObjectID, []primitive.ObjectID) {
+	members := make([]primitive.ObjectID, 0, len(c.Members))
+	for _, member := range c.Members {
+		if member != userId {
+			members = append(members, member)
+		}
+	}
+	admins := make([]primitive.ObjectID, 0, len(c.Admins))
+	for _, admin := range c.Admins {
+		if admin != userId {
+			admins = append(admins, admin)
+		}
+	}
+	return members, admins
+}
+
+// WithoutUser is like WithoutMember but keeps the channel administrated, the remaining
+// members that joined first and are not admins yet are promoted while below ADMINS_MINIMUM.
+func (c *Channel) WithoutUser(userId primitive.ObjectID) ([]primitive.ObjectID, []primitive.ObjectID) {
+	members, admins := c.WithoutMember(userId)
+	for _, member := range members {
+		if len(admins) >= ADMINS_MINIMUM {
+			break
+		}
+		if !slices.Contains(admins, member) {
+			admins = append(admins, member)
+		}
+	}
+	return members, admins
+}
+
+func (c *Channel) IsMember(userId primitive.ObjectID) bool {
+	for _, member := range c.Members {
+		if member == userId {
+			return true
+		}
+	}
+	return false
+}
+
 func ValidateUserId(userId string) error {
 	_, err := primitive.ObjectIDFromHex(userId)
 	if err != nil {
@@ -110,22 +432,61 @@ func ParseUserIds(userIds []string) ([]primitive.ObjectID, error) {
 	return parsedUserIds, nil
 }
 
+func ParseCategoryIds(categoryIds []string) ([]primitive.ObjectID, error) {
+	var parsedCategoryIds []primitive.ObjectID = make([]primitive.ObjectID, 0)
+	for _, categoryId := range categoryIds {
+		parsedId, err := primitive.ObjectIDFromHex(categoryId)
+		if err != nil {
+			return nil, exceptions.New(exceptions.ErrInvalidCategoriesField, err)
+		}
+		parsedCategoryIds = append(parsedCategoryIds, parsedId)
+	}
+
+	return parsedCategoryIds, nil
+}
+
+func IsValidVisibility(visibility string) bool {
+	return visibility == VISIBILITY_PUBLIC || visibility == VISIBILITY_PRIVATE
+}
+
 func (r *ChannelRequest) ToChannel() *Channel {
 	members, _ := ParseUserIds(r.Members)
 	admins, _ := ParseUserIds(r.Admins)
+	categories, _ := ParseCategoryIds(r.Categories)
+	visibility := r.Visibility
+	if visibility == "" {
+		visibility = VISIBILITY_PRIVATE
+	}
+	var location *GeoPoint
+	if r.Location != nil {
+		location, _ = ParseLocation(r.Location)
+	}
 	return &Channel{
-		Name:        r.Name,
-		Description: r.Description,
-		Members:     members,
-		Admins:      admins,
+		Name:              r.Name,
+		Description:       r.Description,
+		Members:           members,
+		Admins:            admins,
+		MemberCount:       len(members),
+		RetentionDays:     r.RetentionDays,
+		Categories:        categories,
+		Visibility:        visibility,
+		Location:          location,
+		SlowModeSeconds:   r.SlowModeSeconds,
+		MessagesPerMinute: r.MessagesPerMinute,
 	}
 }
 
 type ChannelPatchRequest struct {
-	Name        *string   `json:"name"`
-	Description *string   `json:"description"`
-	Members     *[]string `json:"members"`
-	Admins      *[]string `json:"admins"`
+	Name              *string    `json:"name"`
+	Description       *string    `json:"description"`
+	Members           *[]string  `json:"members"`
+	Admins            *[]string  `json:"admins"`
+	RetentionDays     *int       `json:"retention_days"`
+	Categories        *[]string  `json:"categories"`
+	Visibility        *string    `json:"visibility"`
+	Location          *[]float64 `json:"location"`
+	SlowModeSeconds   *int       `json:"slow_mode_seconds"`
+	MessagesPerMinute *int       `json:"messages_per_minute"`
 }
 
 func (r *ChannelPatchRequest) Validate() error {
@@ -138,6 +499,28 @@ func (r *ChannelPatchRequest) Validate() error {
 	if r.Admins != nil && len(*r.Admins) < ADMINS_MINIMUM {
 		return exceptions.New(exceptions.ErrInvalidAdminsField, nil)
 	}
+	if r.RetentionDays != nil && *r.RetentionDays < 0 {
+		return exceptions.New(exceptions.ErrInvalidRetentionField, nil)
+	}
+	if r.Visibility != nil && !IsValidVisibility(*r.Visibility) {
+		return exceptions.New(exceptions.ErrInvalidVisibilityField, nil)
+	}
+	if r.Categories != nil {
+		if _, err := ParseCategoryIds(*r.Categories); err != nil {
+			return err
+		}
+	}
+	if r.Location != nil && len(*r.Location) > 0 {
+		if _, err := ParseLocation(*r.Location); err != nil {
+			return err
+		}
+	}
+	if r.SlowModeSeconds != nil && *r.SlowModeSeconds < 0 {
+		return exceptions.New(exceptions.ErrInvalidSlowModeField, nil)
+	}
+	if r.MessagesPerMinute != nil && *r.MessagesPerMinute < 0 {
+		return exceptions.New(exceptions.ErrInvalidRateLimitField, nil)
+	}
 
 	var err error
 	members := r.Members
@@ -157,6 +540,30 @@ func (r *ChannelPatchRequest) Validate() error {
 	return err
 }
 
+func (r *ChannelPatchRequest) ChangesMembership() bool {
+	return r.Members != nil || r.Admins != nil
+}
+
+// UserIds returns the members and admins the request sets, nil when it keeps them.
+func (r *ChannelPatchRequest) UserIds() ([]primitive.ObjectID, error) {
+	var ids []primitive.ObjectID
+	if r.Members != nil {
+		members, err := ParseUserIds(*r.Members)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, members...)
+	}
+	if r.Admins != nil {
+		admins, err := ParseUserIds(*r.Admins)
+		if err != nil {
+			return nil, exceptions.New(exceptions.ErrInvalidAdminsField, err)
+		}
+		ids = append(ids, admins...)
+	}
+	return ids, nil
+}
+
 func (r *ChannelPatchRequest) ToBsonM() bson.M {
 	fields := bson.M{}
 	if r.Name != nil {
@@ -173,6 +580,30 @@ func (r *ChannelPatchRequest) ToBsonM() bson.M {
 		parsedAdmins, _ := ParseUserIds(*r.Admins)
 		fields["admins"] = parsedAdmins
 	}
+	if r.RetentionDays != nil {
+		fields["retention_days"] = *r.RetentionDays
+	}
+	if r.Categories != nil {
+		parsedCategories, _ := ParseCategoryIds(*r.Categories)
+		fields["categories"] = parsedCategories
+	}
+	if r.Visibility != nil {
+		fields["visibility"] = *r.Visibility
+	}
+	if r.Location != nil {
+		// an empty location removes the channel from geo queries
+		var location *GeoPoint
+		if len(*r.Location) > 0 {
+			location, _ = ParseLocation(*r.Location)
+		}
+		fields["location"] = location
+	}
+	if r.SlowModeSeconds != nil {
+		fields["slow_mode_seconds"] = *r.SlowModeSeconds
+	}
+	if r.MessagesPerMinute != nil {
+		fields["messages_per_minute"] = *r.MessagesPerMinute
+	}
 
 	response := bson.M{"$set": fields}
 	return response
@@ -182,3 +613,20 @@ const (
 	MEMBERS_MINIMUM = 2
 	ADMINS_MINIMUM  = 1
 )
+
+const (
+	VISIBILITY_PUBLIC  = "public"
+	VISIBILITY_PRIVATE = "private"
+)
+
+// The reasons a channel is flagged for moderation, the flag is cleared once none is left.
+const (
+	FLAG_REASON_CONTENT_POLICY = "content_policy"
+	FLAG_REASON_REPORTS        = "reports"
+)
+
+const (
+	DENUNCIATION_POLICY_EXCLUDE   = "exclude"
+	DENUNCIATION_POLICY_ANONYMIZE = "anonymize"
+	DENUNCIATION_POLICY_FLAG      = "flag"
+)
@@ -0,0 +1,184 @@
+package domain
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// CHANNEL_FIELDS are the fields of a channel a client can select, by their json names.
+var CHANNEL_FIELDS = []string{
+	"id",
+	"created_at",
+	"updated_at",
+	"name",
+	"description",
+	"members",
+	"admins",
+	"member_count",
+	"retention_days",
+	"categories",
+	"visibility",
+	"location",
+	"flagged",
+	"flag_reasons",
+	"slow_mode_seconds",
+	"messages_per_minute",
+	"locked",
+	"lock",
+}
+
+// EXPANDED_CHANNEL_FIELDS can only be selected when listing the channels with their members.
+var EXPANDED_CHANNEL_FIELDS = []string{"members_total", "sanctions"}
+
+// Fields is the selection of fields a client reads from channels, nil selects every field.
+type Fields []string
+
+// ParseChannelFields reads a selection such as name,member_count. The id is always selected.
+func ParseChannelFields(raw string, expanded bool) (Fields, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	fields := Fields{"id"}
+	for _, part := range strings.Split(raw, ",") {
+		field := strings.TrimSpace(part)
+		allowed := slices.Contains(CHANNEL_FIELDS, field) || (expanded && slices.Contains(EXPANDED_CHANNEL_FIELDS, field))
+		if !allowed {
+			return nil, exceptions.New(exceptions.ErrInvalidFieldsParam, fmt.Errorf("field %q", part))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// Projection returns the stored fields to read for the selection, along with the ones the service
+// relies on: the sort keys to position cursors, the lock to refresh it and the admins to attach sanctions.
+func (f Fields) Projection(sort []SortKey) bson.M {
+	if f == nil {
+		return nil
+	}
+	projection := bson.M{}
+	for _, field := range f {
+		switch field {
+		case "id":
+			projection["_id"] = 1
+		case "locked", "lock":
+			projection["locked"] = 1
+			projection["lock"] = 1
+		case "sanctions":
+			projection["admins"] = 1
+		default:
+			projection[field] = 1
+		}
+	}
+	for _, key := range sort {
+		projection[key.Field] = 1
+	}
+	return projection
+}
+
+// Select keeps only the selected fields of a channel, or of each channel of a list, by their json names.
+// The values keep their types, so they are encoded the same as in the whole channel.
+func (f Fields) Select(value interface{}) (ChannelResponseGeneral, error) {
+	if f == nil {
+		return value, nil
+	}
+	return f.selectValue(reflect.ValueOf(value))
+}
+
+func (f Fields) selectValue(value reflect.Value) (interface{}, error) {
+	for value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil, nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		selected := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			item, err := f.selectValue(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			selected = append(selected, item)
+		}
+		return selected, nil
+	case reflect.Struct:
+		selected := make(map[string]interface{}, len(f))
+		f.keep(value, selected)
+		return selected, nil
+	}
+	return nil, fmt.Errorf("cannot select fields of a %s", value.Type())
+}
+
+// keep copies the selected fields of the struct into selected, following the encoding/json rules:
+// embedded structs without a name are inlined and the fields of the outer struct win over theirs.
+func (f Fields) keep(value reflect.Value, selected map[string]interface{}) {
+	valueType := value.Type()
+	var embedded []reflect.Value
+	defined := make(map[string]bool)
+	for i := 0; i < valueType.NumField(); i++ {
+		field := valueType.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			embedded = append(embedded, value.Field(i))
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		defined[name] = true
+		if !slices.Contains(f, name) {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if slices.Contains(strings.Split(options, ","), "omitempty") && isEmptyValue(fieldValue) {
+			continue
+		}
+		selected[name] = fieldValue.Interface()
+	}
+
+	for _, inner := range embedded {
+		innerSelected := make(map[string]interface{})
+		f.keep(inner, innerSelected)
+		for name, fieldValue := range innerSelected {
+			if !defined[name] {
+				selected[name] = fieldValue
+			}
+		}
+	}
+}
+
+// isEmptyValue tells whether encoding/json leaves the field out under omitempty.
+func isEmptyValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return value.Len() == 0
+	case reflect.Bool:
+		return !value.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return value.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return value.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return value.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return value.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const CONTENT_MAXIMUM = 4000
+
+type Message struct {
+	mongorm.Model `bson:",inline"`
+	ChannelId     primitive.ObjectID `json:"channel_id" bson:"channel_id"`
+	SenderId      primitive.ObjectID `json:"sender_id" bson:"sender_id"`
+	Content       string             `json:"content" bson:"content"`
+	Flagged       bool               `json:"flagged" bson:"flagged"`
+}
+
+// RateWindow counts the messages posted in a channel during a fixed window, it expires once the window is over.
+type RateWindow struct {
+	mongorm.Model `bson:",inline"`
+	ChannelId     primitive.ObjectID `json:"channel_id" bson:"channel_id"`
+	WindowStart   time.Time          `json:"window_start" bson:"window_start"`
+	Count         int                `json:"count" bson:"count"`
+	ExpiresAt     time.Time          `json:"expires_at" bson:"expires_at"`
+}
+
+// SlowModeSlot holds when a member may post again in a channel in slow mode, it expires at that instant.
+type SlowModeSlot struct {
+	mongorm.Model `bson:",inline"`
+	ChannelId     primitive.ObjectID `json:"channel_id" bson:"channel_id"`
+	MemberId      primitive.ObjectID `json:"member_id" bson:"member_id"`
+	NextPostAt    time.Time          `json:"next_post_at" bson:"next_post_at"`
+}
+
+type MessageRequest struct {
+	Content string `json:"content"`
+}
+
+func (r *MessageRequest) Validate() error {
+	if r.Content == "" || len(r.Content) > CONTENT_MAXIMUM {
+		return exceptions.New(exceptions.ErrInvalidContentField, nil)
+	}
+	return nil
+}
+
+func (r *MessageRequest) ToMessage(channelId primitive.ObjectID, senderId primitive.ObjectID) *Message {
+	return &Message{
+		ChannelId: channelId,
+		SenderId:  senderId,
+		Content:   r.Content,
+	}
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+)
+
+// OutboxEntry is an event waiting to be delivered, stored in the same transaction as the change it describes.
+type OutboxEntry struct {
+	mongorm.Model `bson:",inline"`
+	EventId       string     `json:"event_id" bson:"event_id"`
+	EventType     string     `json:"event_type" bson:"event_type"`
+	ChannelId     string     `json:"channel_id" bson:"channel_id"`
+	Payload       string     `json:"payload" bson:"payload"`
+	Attempts      int        `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at" bson:"next_attempt_at"`
+	LastError     string     `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+	// DeadAt is set once the entry ran out of attempts, it is kept for inspection and not retried
+	DeadAt *time.Time `json:"dead_at,omitempty" bson:"dead_at,omitempty"`
+}
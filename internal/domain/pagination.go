@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+)
+
+// SortKey orders a listing by a field, the last key of a sort is always _id so the order is total.
+type SortKey struct {
+	Field      string
+	Descending bool
+}
+
+// DEFAULT_CHANNEL_SORT lists the channels changed most recently first.
+var DEFAULT_CHANNEL_SORT = []SortKey{{Field: "updated_at", Descending: true}, {Field: "_id", Descending: true}}
+
+// CHANNEL_SORT_FIELDS are the fields channels can be sorted by. Each one alone is backed by an index
+// on members, the field and _id, which serves both directions since _id follows the field.
+var CHANNEL_SORT_FIELDS = []string{"created_at", "updated_at", "name", "member_count"}
+
+// CHANNEL_INDEXED_SORTS are the sorts on several fields backed by an index, which also serves the
+// sort with every direction reversed. Other combinations are valid but sorted in memory over the
+// channels of the user.
+var CHANNEL_INDEXED_SORTS = []string{"member_count:desc,updated_at:desc", "name:asc,created_at:desc"}
+
+const (
+	SORT_ASCENDING  = "asc"
+	SORT_DESCENDING = "desc"
+)
+
+// ParseChannelSort reads a sort such as member_count:desc,name, where the direction defaults to
+// ascending and a field appears once. _id is appended in the direction of the last key so channels
+// with equal keys keep a stable order across pages.
+func ParseChannelSort(raw string) ([]SortKey, error) {
+	keys := make([]SortKey, 0)
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		field, direction, _ := strings.Cut(strings.TrimSpace(part), ":")
+		if !slices.Contains(CHANNEL_SORT_FIELDS, field) || seen[field] {
+			return nil, exceptions.New(exceptions.ErrInvalidSortField, fmt.Errorf("sort field %q", part))
+		}
+		if direction != "" && direction != SORT_ASCENDING && direction != SORT_DESCENDING {
+			return nil, exceptions.New(exceptions.ErrInvalidSortField, fmt.Errorf("sort direction %q", part))
+		}
+		seen[field] = true
+		keys = append(keys, SortKey{Field: field, Descending: direction == SORT_DESCENDING})
+	}
+	return append(keys, SortKey{Field: "_id", Descending: keys[len(keys)-1].Descending}), nil
+}
+
+// ChannelCursor is the position a page starts after, or before when Backward. Values holds the
+// sort keys of the channel at that position and Sort the sort it was issued for.
+type ChannelCursor struct {
+	Sort     string        `bson:"sort"`
+	Values   []interface{} `bson:"values"`
+	Backward bool          `bson:"backward"`
+}
+
+// Page selects a page of a listing by cursor or, deprecated, by page number.
+type Page struct {
+	Limit  int64
+	Offset int64
+	Sort   []SortKey
+	Cursor *ChannelCursor
+}
+
+func (p *Page) IsBackward() bool {
+	return p.Cursor != nil && p.Cursor.Backward
+}
+
+// SortSpec renders the sort as it identifies cursors, e.g. updated_at:desc,_id:desc.
+func SortSpec(keys []SortKey) string {
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		direction := SORT_ASCENDING
+		if key.Descending {
+			direction = SORT_DESCENDING
+		}
+		parts = append(parts, fmt.Sprintf("%s:%s", key.Field, direction))
+	}
+	return strings.Join(parts, ",")
+}
+
+// CursorAt returns the cursor positioned on the channel for the sort.
+func CursorAt(channel *Channel, keys []SortKey, backward bool) *ChannelCursor {
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, channel.SortValue(key.Field))
+	}
+	return &ChannelCursor{
+		Sort:     SortSpec(keys),
+		Values:   values,
+		Backward: backward,
+	}
+}
+
+func (c *Channel) SortValue(field string) interface{} {
+	switch field {
+	case "updated_at":
+		return c.UpdatedAt
+	case "created_at":
+		return c.CreatedAt
+	case "name":
+		return c.Name
+	case "member_count":
+		return c.MemberCount
+	default:
+		return c.ID
+	}
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	REPORT_TARGET_CHANNEL = "channel"
+	REPORT_TARGET_MEMBER  = "member"
+	REPORT_TARGET_MESSAGE = "message"
+)
+
+const (
+	REPORT_STATUS_OPEN     = "open"
+	REPORT_STATUS_ASSIGNED = "assigned"
+	REPORT_STATUS_RESOLVED = "resolved"
+)
+
+const (
+	REPORT_ACTION_DISMISSED      = "dismissed"
+	REPORT_ACTION_WARNED         = "warned"
+	REPORT_ACTION_CONTENT_REMOVE = "content_removed"
+	REPORT_ACTION_MEMBER_REMOVED = "member_removed"
+	REPORT_ACTION_CHANNEL_DELETE = "channel_deleted"
+)
+
+const REASON_MINIMUM = 3
+
+type Report struct {
+	mongorm.Model  `bson:",inline"`
+	ChannelId      primitive.ObjectID  `json:"channel_id" bson:"channel_id"`
+	TargetType     string              `json:"target_type" bson:"target_type"`
+	TargetId       primitive.ObjectID  `json:"target_id" bson:"target_id"`
+	ReporterId     primitive.ObjectID  `json:"reporter_id" bson:"reporter_id"`
+	Reason         string              `json:"reason" bson:"reason"`
+	Status         string              `json:"status" bson:"status"`
+	Flagged        bool                `json:"flagged" bson:"flagged"`
+	AssigneeId     *primitive.ObjectID `json:"assignee_id,omitempty" bson:"assignee_id,omitempty"`
+	Action         string              `json:"action,omitempty" bson:"action,omitempty"`
+	ResolutionNote string              `json:"resolution_note,omitempty" bson:"resolution_note,omitempty"`
+	ResolvedBy     *primitive.ObjectID `json:"resolved_by,omitempty" bson:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time          `json:"resolved_at,omitempty" bson:"resolved_at,omitempty"`
+}
+
+type ReportResponse struct {
+	Reports  []*Report `json:"reports"`
+	NextPage int64     `json:"next_page,omitempty"`
+}
+
+type ReportRequest struct {
+	TargetType string `json:"target_type"`
+	TargetId   string `json:"target_id"`
+	Reason     string `json:"reason"`
+}
+
+func (r *ReportRequest) Validate() error {
+	switch r.TargetType {
+	case REPORT_TARGET_CHANNEL:
+	case REPORT_TARGET_MEMBER, REPORT_TARGET_MESSAGE:
+		if _, err := primitive.ObjectIDFromHex(r.TargetId); err != nil {
+			return exceptions.New(exceptions.ErrInvalidReportTarget, err)
+		}
+	default:
+		return exceptions.New(exceptions.ErrInvalidReportTarget, nil)
+	}
+	if len(r.Reason) < REASON_MINIMUM {
+		return exceptions.New(exceptions.ErrInvalidReasonField, nil)
+	}
+	return nil
+}
+
+// ToReport builds an open report; channel reports target the channel itself.
+func (r *ReportRequest) ToReport(channelId primitive.ObjectID, reporterId primitive.ObjectID) *Report {
+	targetId := channelId
+	if r.TargetType != REPORT_TARGET_CHANNEL {
+		targetId, _ = primitive.ObjectIDFromHex(r.TargetId)
+	}
+	return &Report{
+		ChannelId:  channelId,
+		TargetType: r.TargetType,
+		TargetId:   targetId,
+		ReporterId: reporterId,
+		Reason:     r.Reason,
+		Status:     REPORT_STATUS_OPEN,
+	}
+}
+
+type ReportAssignRequest struct {
+	AssigneeId string `json:"assignee_id"`
+}
+
+func (r *ReportAssignRequest) Validate() error {
+	return ValidateUserId(r.AssigneeId)
+}
+
+type ReportResolveRequest struct {
+	Action string `json:"action"`
+	Note   string `json:"note"`
+}
+
+func (r *ReportResolveRequest) Validate() error {
+	switch r.Action {
+	case REPORT_ACTION_DISMISSED,
+		REPORT_ACTION_WARNED,
+		REPORT_ACTION_CONTENT_REMOVE,
+		REPORT_ACTION_MEMBER_REMOVED,
+		REPORT_ACTION_CHANNEL_DELETE:
+		return nil
+	default:
+		return exceptions.New(exceptions.ErrInvalidReportAction, nil)
+	}
+}
+
+func IsValidReportStatus(status string) bool {
+	return status == REPORT_STATUS_OPEN || status == REPORT_STATUS_ASSIGNED || status == REPORT_STATUS_RESOLVED
+}
@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	SANCTION_TIMEOUT = "timeout"
+	SANCTION_KICK    = "kick"
+)
+
+type Sanction struct {
+	mongorm.Model `bson:",inline"`
+	ChannelId     primitive.ObjectID `json:"channel_id" bson:"channel_id"`
+	MemberId      primitive.ObjectID `json:"member_id" bson:"member_id"`
+	IssuedBy      primitive.ObjectID `json:"issued_by" bson:"issued_by"`
+	Type          string             `json:"type" bson:"type"`
+	Reason        string             `json:"reason" bson:"reason"`
+	Until         *time.Time         `json:"until,omitempty" bson:"until,omitempty"`
+}
+
+type TimeoutRequest struct {
+	Until  time.Time `json:"until"`
+	Reason string    `json:"reason"`
+}
+
+func (r *TimeoutRequest) Validate(now time.Time) error {
+	if !r.Until.After(now) {
+		return exceptions.New(exceptions.ErrInvalidUntilField, nil)
+	}
+	if len(r.Reason) < REASON_MINIMUM {
+		return exceptions.New(exceptions.ErrInvalidReasonField, nil)
+	}
+	return nil
+}
+
+func (r *TimeoutRequest) ToSanction(channelId primitive.ObjectID, memberId primitive.ObjectID, issuedBy primitive.ObjectID) *Sanction {
+	until := r.Until
+	return &Sanction{
+		ChannelId: channelId,
+		MemberId:  memberId,
+		IssuedBy:  issuedBy,
+		Type:      SANCTION_TIMEOUT,
+		Reason:    r.Reason,
+		Until:     &until,
+	}
+}
+
+type KickRequest struct {
+	Reason string `json:"reason"`
+}
+
+func (r *KickRequest) Validate() error {
+	if len(r.Reason) < REASON_MINIMUM {
+		return exceptions.New(exceptions.ErrInvalidReasonField, nil)
+	}
+	return nil
+}
+
+func (r *KickRequest) ToSanction(channelId primitive.ObjectID, memberId primitive.ObjectID, issuedBy primitive.ObjectID) *Sanction {
+	return &Sanction{
+		ChannelId: channelId,
+		MemberId:  memberId,
+		IssuedBy:  issuedBy,
+		Type:      SANCTION_KICK,
+		Reason:    r.Reason,
+	}
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	USER_EVENT_CREATED     = "user.created"
+	USER_EVENT_UPDATED     = "user.updated"
+	USER_EVENT_DELETED     = "user.deleted"
+	USER_EVENT_DENUNCIATED = "user.denunciated"
+
+	ANONYMIZED_NICKNAME = "anonymous"
+)
+
+// UserEvent is a lifecycle event published by the users service, User is sent on creations and updates.
+type UserEvent struct {
+	ID         string    `json:"id"`
+	Type       string    `json:"type"`
+	UserId     string    `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	User       *User     `json:"user,omitempty"`
+}
+
+// ProcessedEvent remembers an incoming event already handled, so redeliveries are ignored.
+type ProcessedEvent struct {
+	ID          string    `json:"id" bson:"_id"`
+	Type        string    `json:"type" bson:"type"`
+	ProcessedAt time.Time `json:"processed_at" bson:"processed_at"`
+}
+
+// UserProfile is the local read model of the public profile of a user, maintained from the
+// user events. Version is the time of the last change applied, older changes are discarded.
+// The location and categories are kept for discovery and recommendations only. A deleted user
+// leaves a tombstone holding its id and version, so changes older than the deletion are discarded.
+type UserProfile struct {
+	ID            primitive.ObjectID   `json:"id" bson:"_id"`
+	FirstName     string               `json:"first_name" bson:"first_name"`
+	LastName      string               `json:"last_name" bson:"last_name"`
+	Nickname      string               `json:"nickname" bson:"nickname"`
+	Description   string               `json:"description,omitempty" bson:"description"`
+	IsDenunciated bool                 `json:"is_denunciated" bson:"is_denunciated"`
+	Location      []float64            `json:"-" bson:"location,omitempty"`
+	Categories    []primitive.ObjectID `json:"-" bson:"categories,omitempty"`
+	Deleted       bool                 `json:"-" bson:"deleted,omitempty"`
+	Version       time.Time            `json:"-" bson:"version"`
+}
+
+// Anonymize strips every personal field of the user, keeping only its ID and denunciation state.
+func (u *UserProfile) Anonymize() {
+	*u = UserProfile{
+		ID:            u.ID,
+		Nickname:      ANONYMIZED_NICKNAME,
+		IsDenunciated: u.IsDenunciated,
+		Version:       u.Version,
+	}
+}
+
+func (u *User) ToProfile(version time.Time) *UserProfile {
+	return &UserProfile{
+		ID:            u.ID,
+		FirstName:     u.FirstName,
+		LastName:      u.LastName,
+		Nickname:      u.Nickname,
+		Description:   u.Description,
+		IsDenunciated: u.IsDenunciated,
+		Location:      u.Location,
+		Categories:    u.Categories,
+		Version:       version,
+	}
+}
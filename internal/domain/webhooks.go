@@ -0,0 +1,163 @@
+package domain
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	WEBHOOK_SECRET_MINIMUM = 16
+
+	DELIVERY_STATUS_PENDING   = "pending"
+	DELIVERY_STATUS_DELIVERED = "delivered"
+	DELIVERY_STATUS_FAILED    = "failed"
+)
+
+// Webhook is a subscription of a partner system to the events of a channel.
+// The secret signs the deliveries and is never returned.
+type Webhook struct {
+	mongorm.Model       `bson:",inline"`
+	ChannelId           primitive.ObjectID `json:"channel_id" bson:"channel_id"`
+	URL                 string             `json:"url" bson:"url"`
+	EventTypes          []string           `json:"event_types" bson:"event_types"`
+	Secret              string             `json:"-" bson:"secret"`
+	CreatedBy           primitive.ObjectID `json:"created_by" bson:"created_by"`
+	Enabled             bool               `json:"enabled" bson:"enabled"`
+	ConsecutiveFailures int                `json:"consecutive_failures" bson:"consecutive_failures"`
+	DisabledAt          *time.Time         `json:"disabled_at,omitempty" bson:"disabled_at,omitempty"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []*Webhook `json:"webhooks"`
+}
+
+type WebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+// Validate checks the request, the event types are checked against the known ones by the service.
+func (r *WebhookRequest) Validate() error {
+	if !isValidWebhookUrl(r.URL) {
+		return exceptions.New(exceptions.ErrInvalidUrlField, nil)
+	}
+	if len(r.EventTypes) == 0 {
+		return exceptions.New(exceptions.ErrInvalidEventTypesField, nil)
+	}
+	if len(r.Secret) < WEBHOOK_SECRET_MINIMUM {
+		return exceptions.New(exceptions.ErrInvalidSecretField, nil)
+	}
+	return nil
+}
+
+func (r *WebhookRequest) ToWebhook(channelId primitive.ObjectID, createdBy primitive.ObjectID) *Webhook {
+	return &Webhook{
+		ChannelId:  channelId,
+		URL:        r.URL,
+		EventTypes: r.EventTypes,
+		Secret:     r.Secret,
+		CreatedBy:  createdBy,
+		Enabled:    true,
+	}
+}
+
+type WebhookPatchRequest struct {
+	URL        *string   `json:"url"`
+	EventTypes *[]string `json:"event_types"`
+	Secret     *string   `json:"secret"`
+	Enabled    *bool     `json:"enabled"`
+}
+
+func (r *WebhookPatchRequest) Validate() error {
+	if r.URL == nil && r.EventTypes == nil && r.Secret == nil && r.Enabled == nil {
+		return exceptions.New(exceptions.ErrNoFieldsToUpdate, nil)
+	}
+	if r.URL != nil && !isValidWebhookUrl(*r.URL) {
+		return exceptions.New(exceptions.ErrInvalidUrlField, nil)
+	}
+	if r.EventTypes != nil && len(*r.EventTypes) == 0 {
+		return exceptions.New(exceptions.ErrInvalidEventTypesField, nil)
+	}
+	if r.Secret != nil && len(*r.Secret) < WEBHOOK_SECRET_MINIMUM {
+		return exceptions.New(exceptions.ErrInvalidSecretField, nil)
+	}
+	return nil
+}
+
+// ToBsonM builds the update, enabling a webhook again clears its failure count.
+func (r *WebhookPatchRequest) ToBsonM() bson.M {
+	fields := bson.M{}
+	if r.URL != nil {
+		fields["url"] = *r.URL
+	}
+	if r.EventTypes != nil {
+		fields["event_types"] = *r.EventTypes
+	}
+	if r.Secret != nil {
+		fields["secret"] = *r.Secret
+	}
+
+	update := bson.M{"$set": fields}
+	if r.Enabled != nil {
+		fields["enabled"] = *r.Enabled
+		if *r.Enabled {
+			fields["consecutive_failures"] = 0
+			update["$unset"] = bson.M{"disabled_at": ""}
+		} else {
+			fields["disabled_at"] = time.Now()
+		}
+	}
+	return update
+}
+
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is an event sent, or still to send, to a webhook.
+type WebhookDelivery struct {
+	mongorm.Model  `bson:",inline"`
+	WebhookId      primitive.ObjectID `json:"webhook_id" bson:"webhook_id"`
+	ChannelId      primitive.ObjectID `json:"channel_id" bson:"channel_id"`
+	EventId        string             `json:"event_id" bson:"event_id"`
+	EventType      string             `json:"event_type" bson:"event_type"`
+	Payload        string             `json:"payload" bson:"payload"`
+	Status         string             `json:"status" bson:"status"`
+	Attempts       int                `json:"attempts" bson:"attempts"`
+	NextAttemptAt  time.Time          `json:"next_attempt_at" bson:"next_attempt_at"`
+	ResponseStatus int                `json:"response_status,omitempty" bson:"response_status,omitempty"`
+	LastError      string             `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	DeliveredAt    *time.Time         `json:"delivered_at,omitempty" bson:"delivered_at,omitempty"`
+}
+
+type WebhookDeliveryResponse struct {
+	Deliveries []*WebhookDelivery `json:"deliveries"`
+	NextPage   int64              `json:"next_page,omitempty"`
+}
+
+func IsValidDeliveryStatus(status string) bool {
+	switch status {
+	case DELIVERY_STATUS_PENDING, DELIVERY_STATUS_DELIVERED, DELIVERY_STATUS_FAILED:
+		return true
+	}
+	return false
+}
+
+func isValidWebhookUrl(rawUrl string) bool {
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
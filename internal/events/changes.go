@@ -0,0 +1,76 @@
+package events
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FromChange builds the events of a change read from the channels change stream. The changes
+// have no actor, and the event ids derive from the resume token so a change read again yields
+// the same ids. Without pre-images an update carries no member events and a delete only the id.
+func FromChange(change mongorm.ChangeEvent) ([]Event, error) {
+	before, err := decodeChannel(change.FullDocumentBeforeChange)
+	if err != nil {
+		return nil, err
+	}
+	after, err := decodeChannel(change.FullDocument)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	switch change.OperationType {
+	case mongorm.CHANGE_INSERT:
+		if after == nil {
+			return nil, nil
+		}
+		events = FromChannelChange("", nil, after)
+	case mongorm.CHANGE_UPDATE, mongorm.CHANGE_REPLACE:
+		if after == nil {
+			// the channel was deleted before the lookup, its delete event follows
+			return nil, nil
+		}
+		if before == nil {
+			events = []Event{New(CHANNEL_UPDATED, after.ID, "", channelData(after))}
+		} else {
+			events = FromChannelChange("", before, after)
+		}
+	case mongorm.CHANGE_DELETE:
+		if before == nil {
+			var key struct {
+				ID primitive.ObjectID `bson:"_id"`
+			}
+			if err := bson.Unmarshal(change.DocumentKey, &key); err != nil {
+				return nil, err
+			}
+			before = &domain.Channel{Model: mongorm.Model{ID: key.ID}}
+		}
+		events = FromChannelChange("", before, nil)
+	default:
+		return nil, nil
+	}
+
+	sum := sha256.Sum256(change.ResumeToken)
+	base := hex.EncodeToString(sum[:12])
+	for i := range events {
+		events[i].ID = fmt.Sprintf("%s-%d", base, i)
+	}
+	return events, nil
+}
+
+func decodeChannel(document bson.Raw) (*domain.Channel, error) {
+	if len(document) == 0 {
+		return nil, nil
+	}
+	channel := &domain.Channel{}
+	if err := bson.Unmarshal(document, channel); err != nil {
+		return nil, err
+	}
+	return channel, nil
+}
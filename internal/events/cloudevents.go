@@ -0,0 +1,131 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	CLOUDEVENTS_SPEC_VERSION = "1.0"
+	CLOUDEVENTS_CONTENT_TYPE = "application/cloudevents+json"
+	DATA_CONTENT_TYPE        = "application/json"
+
+	// ENCODING_STRUCTURED puts the whole CloudEvent in the message value, ENCODING_BINARY
+	// puts the attributes in ce_ headers and only the data in the value.
+	ENCODING_STRUCTURED = "structured"
+	ENCODING_BINARY     = "binary"
+)
+
+// CloudEvent is the CloudEvents 1.0 envelope of every emitted event.
+// ActorId is an extension attribute, absent for changes made by the service itself.
+type CloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema,omitempty"`
+	ActorId         string          `json:"actorid,omitempty"`
+	Data            json.RawMessage `json:"data"`
+}
+
+func IsValidEncoding(encoding string) bool {
+	return encoding == ENCODING_STRUCTURED || encoding == ENCODING_BINARY
+}
+
+// CloudEventEncoder wraps events in CloudEvents sourced from this service.
+type CloudEventEncoder struct {
+	source  string
+	schemas SchemaRegistry
+}
+
+func NewCloudEventEncoder(source string, schemas SchemaRegistry) *CloudEventEncoder {
+	return &CloudEventEncoder{source, schemas}
+}
+
+func (e *CloudEventEncoder) Encode(event Event) (CloudEvent, error) {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return CloudEvent{}, err
+	}
+
+	cloudEvent := CloudEvent{
+		SpecVersion:     CLOUDEVENTS_SPEC_VERSION,
+		ID:              event.ID,
+		Source:          e.source,
+		Type:            event.Type,
+		Subject:         event.Subject,
+		Time:            event.OccurredAt,
+		DataContentType: DATA_CONTENT_TYPE,
+		ActorId:         event.ActorId,
+		Data:            data,
+	}
+	if cloudEvent.Subject == "" {
+		cloudEvent.Subject = channelSubject(event.ChannelId)
+	}
+	if schema, ok := e.schemas.Latest(event.Type); ok {
+		cloudEvent.DataSchema = schema.URL
+	}
+	return cloudEvent, nil
+}
+
+// Structured returns the JSON of the CloudEvent, to send with CLOUDEVENTS_CONTENT_TYPE.
+func (e *CloudEventEncoder) Structured(event Event) ([]byte, error) {
+	cloudEvent, err := e.Encode(event)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(cloudEvent)
+}
+
+// KafkaMessage encodes the event following the Kafka protocol binding of CloudEvents,
+// keyed by channel so the events of a channel keep their order.
+func (e *CloudEventEncoder) KafkaMessage(event Event, encoding string) (kafka.Message, error) {
+	cloudEvent, err := e.Encode(event)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	message := kafka.Message{
+		Key: []byte(event.ChannelId),
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(event.Type)},
+		},
+	}
+	if encoding != ENCODING_BINARY {
+		value, err := json.Marshal(cloudEvent)
+		if err != nil {
+			return kafka.Message{}, err
+		}
+		message.Value = value
+		message.Headers = append(message.Headers, kafka.Header{Key: "content-type", Value: []byte(CLOUDEVENTS_CONTENT_TYPE)})
+		return message, nil
+	}
+
+	message.Value = cloudEvent.Data
+	message.Headers = append(message.Headers,
+		kafka.Header{Key: "content-type", Value: []byte(cloudEvent.DataContentType)},
+		kafka.Header{Key: "ce_specversion", Value: []byte(cloudEvent.SpecVersion)},
+		kafka.Header{Key: "ce_id", Value: []byte(cloudEvent.ID)},
+		kafka.Header{Key: "ce_source", Value: []byte(cloudEvent.Source)},
+		kafka.Header{Key: "ce_type", Value: []byte(cloudEvent.Type)},
+		kafka.Header{Key: "ce_subject", Value: []byte(cloudEvent.Subject)},
+		kafka.Header{Key: "ce_time", Value: []byte(cloudEvent.Time.UTC().Format(time.RFC3339Nano))},
+	)
+	if cloudEvent.DataSchema != "" {
+		message.Headers = append(message.Headers, kafka.Header{Key: "ce_dataschema", Value: []byte(cloudEvent.DataSchema)})
+	}
+	if cloudEvent.ActorId != "" {
+		message.Headers = append(message.Headers, kafka.Header{Key: "ce_actorid", Value: []byte(cloudEvent.ActorId)})
+	}
+	return message, nil
+}
+
+func channelSubject(channelId string) string {
+	return fmt.Sprintf("channels/%s", channelId)
+}
@@ -0,0 +1,397 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/segmentio/kafka-go"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const TEST_SOURCE = "/ms-channels-api"
+
+// schemaValidator checks values against the embedded schemas. It implements the keywords the
+// schemas use and fails on any other, so a schema cannot silently go unchecked.
+type schemaValidator struct {
+	registry SchemaRegistry
+}
+
+func (v *schemaValidator) validateType(eventType string, value interface{}) error {
+	info, ok := v.registry.Latest(eventType)
+	if !ok {
+		return fmt.Errorf("no schema for %s", eventType)
+	}
+	root, err := v.load(fmt.Sprintf("%s/%s/%d", SCHEMA_PATH, info.Type, info.Version))
+	if err != nil {
+		return err
+	}
+	return v.validate(root, root, value, "data")
+}
+
+// load reads a schema by its $id, such as /v1/schemas/channel/1.
+func (v *schemaValidator) load(id string) (map[string]interface{}, error) {
+	name, versionText, found := strings.Cut(strings.TrimPrefix(id, SCHEMA_PATH+"/"), "/")
+	if !found {
+		return nil, fmt.Errorf("invalid schema reference %s", id)
+	}
+	version, err := strconv.Atoi(versionText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema reference %s", id)
+	}
+	content, ok := v.registry.Get(name, version)
+	if !ok {
+		return nil, fmt.Errorf("unknown schema %s", id)
+	}
+	var schema map[string]interface{}
+	if err := json.Unmarshal(content, &schema); err != nil {
+		return nil, err
+	}
+	if schema["$id"] != id {
+		return nil, fmt.Errorf("schema %s has $id %v", id, schema["$id"])
+	}
+	return schema, nil
+}
+
+func (v *schemaValidator) validate(root map[string]interface{}, schema map[string]interface{}, value interface{}, path string) error {
+	for keyword, constraint := range schema {
+		var err error
+		switch keyword {
+		case "$schema", "$id", "$defs", "title", "description":
+		case "$ref":
+			// the referenced schema reports the path of its errors
+			if err := v.validateRef(root, constraint.(string), value, path); err != nil {
+				return err
+			}
+		case "type":
+			err = checkType(constraint.(string), value)
+		case "required":
+			object, _ := value.(map[string]interface{})
+			for _, name := range constraint.([]interface{}) {
+				if _, ok := object[name.(string)]; !ok {
+					err = fmt.Errorf("missing %s", name)
+					break
+				}
+			}
+		case "properties":
+			object, _ := value.(map[string]interface{})
+			for name, property := range constraint.(map[string]interface{}) {
+				if field, ok := object[name]; ok {
+					if err = v.validate(root, property.(map[string]interface{}), field, path+"."+name); err != nil {
+						return err
+					}
+				}
+			}
+		case "items":
+			items, _ := value.([]interface{})
+			for i, item := range items {
+				if err = v.validate(root, constraint.(map[string]interface{}), item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		case "enum":
+			err = fmt.Errorf("%v is not one of %v", value, constraint)
+			for _, allowed := range constraint.([]interface{}) {
+				if allowed == value {
+					err = nil
+				}
+			}
+		case "const":
+			if value != constraint {
+				err = fmt.Errorf("%v is not %v", value, constraint)
+			}
+		case "pattern":
+			if text, ok := value.(string); ok && !regexp.MustCompile(constraint.(string)).MatchString(text) {
+				err = fmt.Errorf("%q does not match %s", text, constraint)
+			}
+		case "format":
+			if text, ok := value.(string); ok && constraint == "date-time" {
+				_, err = time.Parse(time.RFC3339Nano, text)
+			}
+		case "minimum":
+			if number, ok := value.(float64); ok && number < constraint.(float64) {
+				err = fmt.Errorf("%v is below %v", number, constraint)
+			}
+		case "maxLength":
+			if text, ok := value.(string); ok && float64(len([]rune(text))) > constraint.(float64) {
+				err = fmt.Errorf("longer than %v", constraint)
+			}
+		case "minItems", "maxItems":
+			items, _ := value.([]interface{})
+			length := float64(len(items))
+			if (keyword == "minItems" && length < constraint.(float64)) || (keyword == "maxItems" && length > constraint.(float64)) {
+				err = fmt.Errorf("%d items, %s is %v", len(items), keyword, constraint)
+			}
+		default:
+			err = fmt.Errorf("unsupported keyword %s", keyword)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+func (v *schemaValidator) validateRef(root map[string]interface{}, ref string, value interface{}, path string) error {
+	if definition, found := strings.CutPrefix(ref, "#/$defs/"); found {
+		defs, _ := root["$defs"].(map[string]interface{})
+		schema, ok := defs[definition].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("unknown definition %s", ref)
+		}
+		return v.validate(root, schema, value, path)
+	}
+	referenced, err := v.load(ref)
+	if err != nil {
+		return err
+	}
+	return v.validate(referenced, referenced, value, path)
+}
+
+func checkType(expected string, value interface{}) error {
+	ok := false
+	switch expected {
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "number":
+		_, ok = value.(float64)
+	case "integer":
+		number, isNumber := value.(float64)
+		ok = isNumber && number == math.Trunc(number)
+	default:
+		return fmt.Errorf("unsupported type %s", expected)
+	}
+	if !ok {
+		return fmt.Errorf("%v is not of type %s", value, expected)
+	}
+	return nil
+}
+
+func newTestEncoder(t *testing.T) (*CloudEventEncoder, *schemaValidator) {
+	t.Helper()
+	registry, err := NewSchemaRegistry("https://channels.example.com/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewCloudEventEncoder(TEST_SOURCE, registry), &schemaValidator{registry}
+}
+
+func newTestChannel() *domain.Channel {
+	now := time.Now().UTC()
+	channel := &domain.Channel{
+		Name:              "general",
+		Description:       "everything else",
+		Members:           []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()},
+		MemberCount:       2,
+		RetentionDays:     30,
+		Categories:        []primitive.ObjectID{primitive.NewObjectID()},
+		Visibility:        domain.VISIBILITY_PUBLIC,
+		Location:          &domain.GeoPoint{Type: "Point", Coordinates: []float64{-46.6, -23.5}},
+		SlowModeSeconds:   10,
+		MessagesPerMinute: 60,
+		Locked:            true,
+		Lock:              &domain.ChannelLock{Reason: "raid", LockedBy: primitive.NewObjectID(), LockedAt: now},
+	}
+	channel.ID = primitive.NewObjectID()
+	channel.CreatedAt = now
+	channel.UpdatedAt = now
+	channel.Admins = channel.Members[:1]
+	return channel
+}
+
+// testEvents returns an event of every type, the channel changes made on a legacy channel
+// stored without visibility nor lists.
+func testEvents() []Event {
+	channel := newTestChannel()
+	legacy := &domain.Channel{Name: "legacy"}
+	legacy.ID = primitive.NewObjectID()
+	legacy.CreatedAt = time.Now().UTC()
+	legacy.UpdatedAt = legacy.CreatedAt
+	joined := *legacy
+	joined.Members = []primitive.ObjectID{primitive.NewObjectID()}
+	left := *channel
+	left.Members = channel.Members[:1]
+
+	actorId := primitive.NewObjectID().Hex()
+	published := FromChannelChange(actorId, nil, channel)
+	published = append(published, FromChannelChange(actorId, legacy, &joined)...)
+	published = append(published, FromChannelChange(actorId, channel, &left)...)
+	published = append(published, FromChannelChange(actorId, legacy, nil)...)
+
+	until := time.Now().UTC().Add(time.Hour)
+	for _, sanctionType := range []string{domain.SANCTION_TIMEOUT, domain.SANCTION_KICK} {
+		sanction := &domain.Sanction{ChannelId: channel.ID, MemberId: channel.Members[1], IssuedBy: channel.Members[0], Type: sanctionType, Reason: "spam"}
+		sanction.ID = primitive.NewObjectID()
+		sanction.CreatedAt = until.Add(-time.Hour)
+		sanction.UpdatedAt = sanction.CreatedAt
+		if sanctionType == domain.SANCTION_TIMEOUT {
+			sanction.Until = &until
+		}
+		published = append(published, FromSanction(sanction))
+	}
+
+	message := &domain.Message{ChannelId: channel.ID, SenderId: channel.Members[0], Content: "hello"}
+	message.ID = primitive.NewObjectID()
+	message.CreatedAt = time.Now().UTC()
+	message.UpdatedAt = message.CreatedAt
+	return append(published, FromMessage(message))
+}
+
+func TestStructuredEventsMatchSchemas(t *testing.T) {
+	encoder, validator := newTestEncoder(t)
+	covered := make(map[string]bool)
+	for _, event := range testEvents() {
+		value, err := encoder.Structured(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cloudEvent map[string]interface{}
+		if err := json.Unmarshal(value, &cloudEvent); err != nil {
+			t.Fatal(err)
+		}
+
+		schema, _ := encoder.schemas.Latest(event.Type)
+		expected := map[string]interface{}{
+			"specversion":     CLOUDEVENTS_SPEC_VERSION,
+			"id":              event.ID,
+			"source":          TEST_SOURCE,
+			"type":            event.Type,
+			"datacontenttype": DATA_CONTENT_TYPE,
+			"dataschema":      schema.URL,
+			"actorid":         event.ActorId,
+		}
+		for attribute, want := range expected {
+			if cloudEvent[attribute] != want {
+				t.Errorf("%s: %s is %v, want %v", event.Type, attribute, cloudEvent[attribute], want)
+			}
+		}
+		subject, _ := cloudEvent["subject"].(string)
+		if !strings.HasPrefix(subject, "channels/"+event.ChannelId) {
+			t.Errorf("%s: subject %q is not under the channel", event.Type, subject)
+		}
+		if _, err := time.Parse(time.RFC3339Nano, cloudEvent["time"].(string)); err != nil {
+			t.Errorf("%s: time: %v", event.Type, err)
+		}
+		if err := validator.validateType(event.Type, cloudEvent["data"]); err != nil {
+			t.Errorf("%s does not match its schema: %v", event.Type, err)
+		}
+		covered[event.Type] = true
+	}
+	for _, eventType := range TYPES {
+		if !covered[eventType] {
+			t.Errorf("no event of type %s was checked", eventType)
+		}
+	}
+}
+
+func TestSchemasRejectInvalidData(t *testing.T) {
+	_, validator := newTestEncoder(t)
+	legacy := &domain.Channel{Name: "legacy"}
+	legacy.ID = primitive.NewObjectID()
+
+	cases := map[string]struct {
+		eventType string
+		data      interface{}
+	}{
+		"legacy channel as stored": {CHANNEL_UPDATED, legacy},
+		"member without id":        {CHANNEL_MEMBER_ADDED, map[string]string{}},
+		"malformed member id":      {CHANNEL_MEMBER_REMOVED, MemberData{"not-an-id"}},
+	}
+	for name, c := range cases {
+		encoded, err := json.Marshal(c.data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var data interface{}
+		if err := json.Unmarshal(encoded, &data); err != nil {
+			t.Fatal(err)
+		}
+		if err := validator.validateType(c.eventType, data); err == nil {
+			t.Errorf("%s matches the %s schema", name, c.eventType)
+		}
+	}
+}
+
+func headers(message kafka.Message) map[string]string {
+	values := make(map[string]string)
+	for _, header := range message.Headers {
+		values[header.Key] = string(header.Value)
+	}
+	return values
+}
+
+func TestKafkaMessageEncodings(t *testing.T) {
+	encoder, validator := newTestEncoder(t)
+	event := testEvents()[0]
+	cloudEvent, err := encoder.Encode(event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	binary, err := encoder.KafkaMessage(event, ENCODING_BINARY)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(binary.Key) != event.ChannelId {
+		t.Errorf("binary message keyed by %q, want the channel", binary.Key)
+	}
+	if string(binary.Value) != string(cloudEvent.Data) {
+		t.Errorf("binary message value is %s, want the data only", binary.Value)
+	}
+	expected := map[string]string{
+		"event_type":     event.Type,
+		"content-type":   DATA_CONTENT_TYPE,
+		"ce_specversion": CLOUDEVENTS_SPEC_VERSION,
+		"ce_id":          event.ID,
+		"ce_source":      TEST_SOURCE,
+		"ce_type":        event.Type,
+		"ce_subject":     cloudEvent.Subject,
+		"ce_time":        cloudEvent.Time.UTC().Format(time.RFC3339Nano),
+		"ce_dataschema":  cloudEvent.DataSchema,
+		"ce_actorid":     event.ActorId,
+	}
+	binaryHeaders := headers(binary)
+	for key, want := range expected {
+		if binaryHeaders[key] != want {
+			t.Errorf("binary header %s is %q, want %q", key, binaryHeaders[key], want)
+		}
+	}
+	var data interface{}
+	if err := json.Unmarshal(binary.Value, &data); err != nil {
+		t.Fatal(err)
+	}
+	if err := validator.validateType(event.Type, data); err != nil {
+		t.Errorf("binary data does not match its schema: %v", err)
+	}
+
+	structured, err := encoder.KafkaMessage(event, ENCODING_STRUCTURED)
+	if err != nil {
+		t.Fatal(err)
+	}
+	structuredHeaders := headers(structured)
+	if structuredHeaders["content-type"] != CLOUDEVENTS_CONTENT_TYPE || structuredHeaders["event_type"] != event.Type {
+		t.Errorf("structured headers %v", structuredHeaders)
+	}
+	if _, found := structuredHeaders["ce_id"]; found {
+		t.Error("structured message carries binary attributes")
+	}
+	var decoded CloudEvent
+	if err := json.Unmarshal(structured.Value, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != event.ID || decoded.Type != event.Type || decoded.DataSchema != cloudEvent.DataSchema || string(decoded.Data) != string(cloudEvent.Data) {
+		t.Errorf("structured message decodes to %+v", decoded)
+	}
+}
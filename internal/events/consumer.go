@@ -0,0 +1,151 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/backoff"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	CONSUMER_RETRY_BASE_DELAY = time.Second
+	CONSUMER_RETRY_MAX_DELAY  = time.Minute
+
+	// headers added to the messages sent to the dead letter topic
+	HEADER_DEAD_LETTER_ERROR     = "dead-letter-error"
+	HEADER_DEAD_LETTER_TOPIC     = "dead-letter-topic"
+	HEADER_DEAD_LETTER_PARTITION = "dead-letter-partition"
+	HEADER_DEAD_LETTER_OFFSET    = "dead-letter-offset"
+)
+
+// MessageHandler handles the value of a consumed message. It must be idempotent, a message
+// is redelivered when the consumer stops between handling it and committing its offset.
+type MessageHandler func(ctx context.Context, value []byte) error
+
+// EventConsumer feeds the messages of a topic to a handler.
+type EventConsumer interface {
+	Start(ctx context.Context)
+	Close() error
+}
+
+// NewConsumer returns a Kafka consumer, or a no-op one when no broker is configured.
+func NewConsumer(brokers string, topic string, groupId string, handler MessageHandler, deadLetterTopic string, maxAttempts int) EventConsumer {
+	if brokers == "" {
+		return NoopConsumer{}
+	}
+	return NewKafkaConsumer(brokers, topic, groupId, handler, deadLetterTopic, maxAttempts)
+}
+
+type KafkaConsumer struct {
+	reader      *kafka.Reader
+	deadLetter  *kafka.Writer
+	handler     MessageHandler
+	maxAttempts int
+}
+
+// NewKafkaConsumer reads the topic within the consumer group, offsets are committed explicitly.
+// Messages failing maxAttempts times are moved to deadLetterTopic.
+func NewKafkaConsumer(brokers string, topic string, groupId string, handler MessageHandler, deadLetterTopic string, maxAttempts int) EventConsumer {
+	return &KafkaConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: strings.Split(brokers, ","),
+			Topic:   topic,
+			GroupID: groupId,
+		}),
+		deadLetter: &kafka.Writer{
+			Addr:                   kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:                  deadLetterTopic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		handler:     handler,
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Start consumes until ctx is cancelled. The offset of a message is only committed once it was
+// handled or moved to the dead letter topic, a failing message is retried with backoff up to
+// maxAttempts times so the following ones keep their order without being blocked for good.
+func (c *KafkaConsumer) Start(ctx context.Context) {
+	for {
+		message, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, kafka.ErrGroupClosed) {
+				return
+			}
+			log.Println("failed to fetch message:", err)
+			continue
+		}
+
+		for attempt := 0; ; attempt++ {
+			err = c.handler(ctx, message.Value)
+			if err == nil {
+				break
+			}
+			log.Printf("failed to handle message at offset %d: %v", message.Offset, err)
+			if attempt+1 >= c.maxAttempts {
+				if !c.moveToDeadLetter(ctx, message, err) {
+					return
+				}
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff.Exponential(attempt, CONSUMER_RETRY_BASE_DELAY, CONSUMER_RETRY_MAX_DELAY)):
+			}
+		}
+
+		if err := c.reader.CommitMessages(ctx, message); err != nil {
+			log.Println("failed to commit message:", err)
+		}
+	}
+}
+
+// moveToDeadLetter writes the message to the dead letter topic along with why it failed, retrying
+// until it is written so no message is lost. It returns false when ctx is cancelled first.
+func (c *KafkaConsumer) moveToDeadLetter(ctx context.Context, message kafka.Message, cause error) bool {
+	deadLetter := kafka.Message{
+		Key:   message.Key,
+		Value: message.Value,
+		Headers: append(slices.Clone(message.Headers),
+			kafka.Header{Key: HEADER_DEAD_LETTER_ERROR, Value: []byte(cause.Error())},
+			kafka.Header{Key: HEADER_DEAD_LETTER_TOPIC, Value: []byte(message.Topic)},
+			kafka.Header{Key: HEADER_DEAD_LETTER_PARTITION, Value: []byte(strconv.Itoa(message.Partition))},
+			kafka.Header{Key: HEADER_DEAD_LETTER_OFFSET, Value: []byte(strconv.FormatInt(message.Offset, 10))},
+		),
+	}
+	for attempt := 0; ; attempt++ {
+		err := c.deadLetter.WriteMessages(ctx, deadLetter)
+		if err == nil {
+			log.Printf("moved message at offset %d to %s after %d attempts", message.Offset, c.deadLetter.Topic, c.maxAttempts)
+			return true
+		}
+		log.Printf("failed to move message at offset %d to %s: %v", message.Offset, c.deadLetter.Topic, err)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(backoff.Exponential(attempt, CONSUMER_RETRY_BASE_DELAY, CONSUMER_RETRY_MAX_DELAY)):
+		}
+	}
+}
+
+func (c *KafkaConsumer) Close() error {
+	return errors.Join(c.reader.Close(), c.deadLetter.Close())
+}
+
+// NoopConsumer consumes nothing, it is used when Kafka is not configured.
+type NoopConsumer struct{}
+
+func (NoopConsumer) Start(ctx context.Context) {}
+
+func (NoopConsumer) Close() error {
+	return nil
+}
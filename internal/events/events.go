@@ -0,0 +1,154 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	CHANNEL_CREATED          = "channel.created"
+	CHANNEL_UPDATED          = "channel.updated"
+	CHANNEL_DELETED          = "channel.deleted"
+	CHANNEL_MEMBER_ADDED     = "channel.member_added"
+	CHANNEL_MEMBER_REMOVED   = "channel.member_removed"
+	CHANNEL_MEMBER_KICKED    = "channel.member_kicked"
+	CHANNEL_MEMBER_TIMED_OUT = "channel.member_timed_out"
+	CHANNEL_MESSAGE_POSTED   = "channel.message_posted"
+)
+
+var TYPES = []string{
+	CHANNEL_CREATED,
+	CHANNEL_UPDATED,
+	CHANNEL_DELETED,
+	CHANNEL_MEMBER_ADDED,
+	CHANNEL_MEMBER_REMOVED,
+	CHANNEL_MEMBER_KICKED,
+	CHANNEL_MEMBER_TIMED_OUT,
+	CHANNEL_MESSAGE_POSTED,
+}
+
+func IsValidType(eventType string) bool {
+	for _, t := range TYPES {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type Event struct {
+	ID         string      `json:"id"`
+	Type       string      `json:"type"`
+	ChannelId  string      `json:"channel_id"`
+	Subject    string      `json:"subject,omitempty"`
+	ActorId    string      `json:"actor_id,omitempty"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+type MemberData struct {
+	MemberId string `json:"member_id"`
+}
+
+// EventPublisher delivers channel domain events to the interested consumers.
+type EventPublisher interface {
+	Publish(ctx context.Context, events ...Event) error
+	Close() error
+}
+
+// NewPublisher returns a Kafka publisher, or a no-op one when no broker is configured.
+func NewPublisher(brokers string, topic string, encoder *CloudEventEncoder, encoding string) EventPublisher {
+	if brokers == "" {
+		return NewNoopPublisher()
+	}
+	return NewKafkaPublisher(brokers, topic, encoder, encoding)
+}
+
+func New(eventType string, channelId primitive.ObjectID, actorId string, data interface{}) Event {
+	return Event{
+		ID:         primitive.NewObjectID().Hex(),
+		Type:       eventType,
+		ChannelId:  channelId.Hex(),
+		ActorId:    actorId,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	}
+}
+
+// FromChannelChange builds the events describing the transition of a channel from before to after,
+// a nil side stands for a channel that does not exist yet or anymore.
+func FromChannelChange(actorId string, before *domain.Channel, after *domain.Channel) []Event {
+	switch {
+	case before == nil:
+		return []Event{New(CHANNEL_CREATED, after.ID, actorId, channelData(after))}
+	case after == nil:
+		return []Event{New(CHANNEL_DELETED, before.ID, actorId, channelData(before))}
+	}
+
+	events := []Event{New(CHANNEL_UPDATED, after.ID, actorId, channelData(after))}
+	for _, member := range difference(after.Members, before.Members) {
+		events = append(events, newMemberEvent(CHANNEL_MEMBER_ADDED, after.ID, actorId, member, MemberData{member.Hex()}))
+	}
+	for _, member := range difference(before.Members, after.Members) {
+		events = append(events, newMemberEvent(CHANNEL_MEMBER_REMOVED, after.ID, actorId, member, MemberData{member.Hex()}))
+	}
+	return events
+}
+
+// channelData fills the fields legacy channels lack so their events match the channel schema:
+// channels stored without a visibility are private and missing lists are empty.
+func channelData(channel *domain.Channel) *domain.Channel {
+	data := *channel
+	if data.Visibility == "" {
+		data.Visibility = domain.VISIBILITY_PRIVATE
+	}
+	if data.Members == nil {
+		data.Members = []primitive.ObjectID{}
+	}
+	if data.Admins == nil {
+		data.Admins = []primitive.ObjectID{}
+	}
+	if data.Categories == nil {
+		data.Categories = []primitive.ObjectID{}
+	}
+	return &data
+}
+
+func FromSanction(sanction *domain.Sanction) Event {
+	eventType := CHANNEL_MEMBER_TIMED_OUT
+	if sanction.Type == domain.SANCTION_KICK {
+		eventType = CHANNEL_MEMBER_KICKED
+	}
+	return newMemberEvent(eventType, sanction.ChannelId, sanction.IssuedBy.Hex(), sanction.MemberId, sanction)
+}
+
+func FromMessage(message *domain.Message) Event {
+	event := New(CHANNEL_MESSAGE_POSTED, message.ChannelId, message.SenderId.Hex(), message)
+	event.Subject = fmt.Sprintf("%s/messages/%s", channelSubject(event.ChannelId), message.ID.Hex())
+	return event
+}
+
+func newMemberEvent(eventType string, channelId primitive.ObjectID, actorId string, memberId primitive.ObjectID, data interface{}) Event {
+	event := New(eventType, channelId, actorId, data)
+	event.Subject = fmt.Sprintf("%s/members/%s", channelSubject(event.ChannelId), memberId.Hex())
+	return event
+}
+
+// difference returns the ids of a that are not in b.
+func difference(a []primitive.ObjectID, b []primitive.ObjectID) []primitive.ObjectID {
+	present := make(map[primitive.ObjectID]bool, len(b))
+	for _, id := range b {
+		present[id] = true
+	}
+	result := make([]primitive.ObjectID, 0)
+	for _, id := range a {
+		if !present[id] {
+			result = append(result, id)
+		}
+	}
+	return result
+}
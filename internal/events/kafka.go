@@ -0,0 +1,45 @@
+package events
+
+import (
+	"context"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type KafkaPublisher struct {
+	writer   *kafka.Writer
+	encoder  *CloudEventEncoder
+	encoding string
+}
+
+// NewKafkaPublisher publishes CloudEvents to the topic keyed by channel, so the events of a channel keep their order.
+func NewKafkaPublisher(brokers string, topic string, encoder *CloudEventEncoder, encoding string) EventPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+		},
+		encoder:  encoder,
+		encoding: encoding,
+	}
+}
+
+func (p *KafkaPublisher) Publish(ctx context.Context, events ...Event) error {
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		message, err := p.encoder.KafkaMessage(event, p.encoding)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	return p.writer.WriteMessages(ctx, messages...)
+}
+
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,33 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// InMemoryPublisher keeps every published event, it is meant for tests.
+type InMemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewInMemoryPublisher() *InMemoryPublisher {
+	return &InMemoryPublisher{}
+}
+
+func (p *InMemoryPublisher) Publish(ctx context.Context, events ...Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *InMemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Event(nil), p.events...)
+}
+
+func (p *InMemoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"errors"
+)
+
+// MultiPublisher hands every event to each of its publishers in turn.
+type MultiPublisher struct {
+	publishers []EventPublisher
+}
+
+func NewMultiPublisher(publishers ...EventPublisher) EventPublisher {
+	return &MultiPublisher{publishers}
+}
+
+// Publish stops at the first failing publisher, so the caller retries the whole batch
+// and the publishers before it see the events again.
+func (p *MultiPublisher) Publish(ctx context.Context, events ...Event) error {
+	for _, publisher := range p.publishers {
+		if err := publisher.Publish(ctx, events...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *MultiPublisher) Close() error {
+	var errs []error
+	for _, publisher := range p.publishers {
+		if err := publisher.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,18 @@
+package events
+
+import "context"
+
+// NoopPublisher discards every event, it is used when Kafka is not configured.
+type NoopPublisher struct{}
+
+func NewNoopPublisher() EventPublisher {
+	return NoopPublisher{}
+}
+
+func (NoopPublisher) Publish(ctx context.Context, events ...Event) error {
+	return nil
+}
+
+func (NoopPublisher) Close() error {
+	return nil
+}
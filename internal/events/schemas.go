@@ -0,0 +1,123 @@
+package events
+
+import (
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SCHEMA_PATH is where the service serves the schemas, see SchemaRegistry.
+const SCHEMA_PATH = "/v1/schemas"
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// SchemaInfo describes the JSON Schema of the data of an event type at a given version.
+type SchemaInfo struct {
+	Type    string `json:"type"`
+	Version int    `json:"version"`
+	URL     string `json:"url"`
+}
+
+type SchemasResponse struct {
+	Schemas []SchemaInfo `json:"schemas"`
+}
+
+// SchemaRegistry holds the versioned data schemas of every event type, named
+// schemas/<type>.v<version>.json. Schemas are immutable once published, a breaking
+// change of the data goes into a new version. Definitions shared by several event types, such as
+// schemas/channel.v1.json, are registered the same way and referenced by their URL path.
+type SchemaRegistry interface {
+	List() []SchemaInfo
+	Get(eventType string, version int) ([]byte, bool)
+	Latest(eventType string) (SchemaInfo, bool)
+}
+
+type EmbeddedSchemaRegistry struct {
+	schemas map[string]map[int][]byte
+	latest  map[string]SchemaInfo
+	baseUrl string
+}
+
+// NewSchemaRegistry loads the embedded schemas, baseUrl is the public URL of the service
+// used to build absolute schema URLs.
+func NewSchemaRegistry(baseUrl string) (SchemaRegistry, error) {
+	registry := &EmbeddedSchemaRegistry{
+		schemas: make(map[string]map[int][]byte),
+		latest:  make(map[string]SchemaInfo),
+		baseUrl: strings.TrimSuffix(baseUrl, "/"),
+	}
+
+	files, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(file.Name(), ".json")
+		separator := strings.LastIndex(name, ".v")
+		if separator < 0 {
+			return nil, fmt.Errorf("schema %s is not named <type>.v<version>.json", file.Name())
+		}
+		eventType := name[:separator]
+		version, err := strconv.Atoi(name[separator+2:])
+		if err != nil {
+			return nil, fmt.Errorf("schema %s has an invalid version: %w", file.Name(), err)
+		}
+		content, err := schemaFiles.ReadFile(path.Join("schemas", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if registry.schemas[eventType] == nil {
+			registry.schemas[eventType] = make(map[int][]byte)
+		}
+		registry.schemas[eventType][version] = content
+		if version > registry.latest[eventType].Version {
+			registry.latest[eventType] = registry.info(eventType, version)
+		}
+	}
+
+	for _, eventType := range TYPES {
+		if _, ok := registry.latest[eventType]; !ok {
+			return nil, fmt.Errorf("missing schema for event type %s", eventType)
+		}
+	}
+	return registry, nil
+}
+
+func (r *EmbeddedSchemaRegistry) List() []SchemaInfo {
+	infos := make([]SchemaInfo, 0)
+	for eventType, versions := range r.schemas {
+		for version := range versions {
+			infos = append(infos, r.info(eventType, version))
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Type != infos[j].Type {
+			return infos[i].Type < infos[j].Type
+		}
+		return infos[i].Version < infos[j].Version
+	})
+	return infos
+}
+
+func (r *EmbeddedSchemaRegistry) Get(eventType string, version int) ([]byte, bool) {
+	content, ok := r.schemas[eventType][version]
+	return content, ok
+}
+
+func (r *EmbeddedSchemaRegistry) Latest(eventType string) (SchemaInfo, bool) {
+	info, ok := r.latest[eventType]
+	return info, ok
+}
+
+func (r *EmbeddedSchemaRegistry) info(eventType string, version int) SchemaInfo {
+	return SchemaInfo{
+		Type:    eventType,
+		Version: version,
+		URL:     fmt.Sprintf("%s%s/%s/%d", r.baseUrl, SCHEMA_PATH, eventType, version),
+	}
+}
@@ -20,15 +20,37 @@ func BindQueryParams(c echo.Context, queryParams *QueryParams) error {
 	return nil
 }
 
+// DEFAULT_RADIUS is the nearby search radius in meters used when none is sent.
+const DEFAULT_RADIUS = 10000
+
+// DEFAULT_MEMBERS_LIMIT and MAX_MEMBERS_LIMIT bound the members expanded per channel when listing with members.
+const (
+	DEFAULT_MEMBERS_LIMIT = 20
+	MAX_MEMBERS_LIMIT     = 100
+)
+
 type QueryParams struct {
 	RawChannelIds string `query:"channel_ids"`
 	RawUserIds    string `query:"user_ids"`
 	ShowMembers   bool   `query:"show_members"`
+	MembersLimit  int64  `query:"members_limit"`
+	Search        string `query:"q"`
 	HeaderUserId  string
 	ChannelIDs    []string
 	UserIds       []string
-	Limit         int64 `query:"limit"`
-	Offset        int64 `query:"next_page"`
+	Limit         int64   `query:"limit"`
+	Offset        int64   `query:"next_page"`
+	Cursor        string  `query:"cursor"`
+	Sort          string  `query:"sort"`
+	Fields        string  `query:"fields"`
+	IncludeTotal  bool    `query:"include_total"`
+	Latitude      string  `query:"lat"`
+	Longitude     string  `query:"lng"`
+	Radius        float64 `query:"radius"`
+	Status        string  `query:"status"`
+	TargetType    string  `query:"target_type"`
+	Actor         string  `query:"actor"`
+	Action        string  `query:"action"`
 }
 
 func (q *QueryParams) normalize() {
@@ -36,10 +58,20 @@ func (q *QueryParams) normalize() {
 	q.UserIds = strings.Split(q.RawUserIds, ",")
 	q.RawUserIds = ""
 	q.RawChannelIds = ""
+	q.Search = strings.TrimSpace(q.Search)
 	if q.Limit < 1 {
 		q.Limit = 10
 	}
 	if q.Offset < 0 {
 		q.Offset = 0
 	}
+	if q.MembersLimit < 1 {
+		q.MembersLimit = DEFAULT_MEMBERS_LIMIT
+	}
+	if q.MembersLimit > MAX_MEMBERS_LIMIT {
+		q.MembersLimit = MAX_MEMBERS_LIMIT
+	}
+	if q.Radius <= 0 {
+		q.Radius = DEFAULT_RADIUS
+	}
 }
@@ -0,0 +1,41 @@
+package audit
+
+import (
+	"net/http"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/audit"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler interface {
+	List(c echo.Context) error
+}
+
+type auditHandler struct {
+	auditService audit.Service
+}
+
+func New(auditService audit.Service) Handler {
+	return &auditHandler{
+		auditService,
+	}
+}
+
+func (h *auditHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryParams helpers.QueryParams
+	err := helpers.BindQueryParams(c, &queryParams)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	events, err := h.auditService.List(ctx, c.Param("id"), queryParams)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, events)
+}
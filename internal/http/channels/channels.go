@@ -1,7 +1,10 @@
 package channels
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
 	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
@@ -16,6 +19,13 @@ type Handler interface {
 	List(c echo.Context) error
 	Update(c echo.Context) error
 	Delete(c echo.Context) error
+	Discover(c echo.Context) error
+	Nearby(c echo.Context) error
+	ListDenunciatedMembers(c echo.Context) error
+	Lock(c echo.Context) error
+	Unlock(c echo.Context) error
+	Timeout(c echo.Context) error
+	Kick(c echo.Context) error
 }
 
 type channelsHandler struct {
@@ -35,7 +45,7 @@ func (h *channelsHandler) Create(c echo.Context) error {
 		return exceptions.New(exceptions.ErrInvalidPayload, err)
 	}
 
-	channel, err := h.channelsService.Create(ctx, channelRequest)
+	channel, err := h.channelsService.Create(ctx, c.Request().Header.Get("user_id"), channelRequest)
 	if err != nil {
 		return err
 	}
@@ -47,7 +57,7 @@ func (h *channelsHandler) Get(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	id := c.Param("id")
-	channel, err := h.channelsService.Get(ctx, id)
+	channel, err := h.channelsService.Get(ctx, id, c.QueryParam("fields"))
 	if err != nil {
 		return err
 	}
@@ -69,6 +79,7 @@ func (h *channelsHandler) List(c echo.Context) error {
 		return err
 	}
 
+	setPaginationHeaders(c, channels)
 	return c.JSON(http.StatusOK, channels)
 }
 
@@ -84,7 +95,7 @@ func (h *channelsHandler) Update(c echo.Context) error {
 		return exceptions.New(exceptions.ErrInvalidID, nil)
 	}
 
-	err := h.channelsService.Update(ctx, id, channelRequest)
+	err := h.channelsService.Update(ctx, id, c.Request().Header.Get("user_id"), channelRequest)
 	if err != nil {
 		return err
 	}
@@ -96,10 +107,172 @@ func (h *channelsHandler) Delete(c echo.Context) error {
 	ctx := c.Request().Context()
 
 	id := c.Param("id")
-	err := h.channelsService.Delete(ctx, id)
+	err := h.channelsService.Delete(ctx, id, c.Request().Header.Get("user_id"))
 	if err != nil {
 		return c.NoContent(http.StatusNoContent)
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+func (h *channelsHandler) Discover(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryParams helpers.QueryParams
+	err := helpers.BindQueryParams(c, &queryParams)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	channels, err := h.channelsService.Discover(ctx, queryParams)
+	if err != nil {
+		return err
+	}
+
+	setPaginationHeaders(c, channels)
+	return c.JSON(http.StatusOK, channels)
+}
+
+func (h *channelsHandler) Nearby(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryParams helpers.QueryParams
+	err := helpers.BindQueryParams(c, &queryParams)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	channels, err := h.channelsService.Nearby(ctx, queryParams)
+	if err != nil {
+		return err
+	}
+
+	setPaginationHeaders(c, channels)
+	return c.JSON(http.StatusOK, channels)
+}
+
+func (h *channelsHandler) ListDenunciatedMembers(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	members, err := h.channelsService.ListDenunciatedMembers(ctx, c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, members)
+}
+
+func (h *channelsHandler) Lock(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var lockRequest domain.LockRequest
+	if err := c.Bind(&lockRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	err := h.channelsService.Lock(ctx, c.Param("id"), userId, lockRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *channelsHandler) Unlock(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	err := h.channelsService.Unlock(ctx, c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *channelsHandler) Timeout(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var timeoutRequest domain.TimeoutRequest
+	if err := c.Bind(&timeoutRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	sanction, err := h.channelsService.Timeout(ctx, c.Param("id"), c.Param("member_id"), userId, timeoutRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, sanction)
+}
+
+func (h *channelsHandler) Kick(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var kickRequest domain.KickRequest
+	if err := c.Bind(&kickRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	sanction, err := h.channelsService.Kick(ctx, c.Param("id"), c.Param("member_id"), userId, kickRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, sanction)
+}
+
+// setPaginationHeaders mirrors the pagination of the listing in the X-Total-Count and Link headers,
+// the links repeat the request with the cursor, or the page number, of the next and previous pages.
+func setPaginationHeaders(c echo.Context, response *domain.ChannelResponse) {
+	if response.Total != nil {
+		c.Response().Header().Set("X-Total-Count", strconv.FormatInt(*response.Total, 10))
+	}
+
+	links := make([]string, 0, 2)
+	if response.NextCursor != "" {
+		links = append(links, pageLink(c, "next", "cursor", response.NextCursor))
+	} else if response.NextPage > 0 {
+		links = append(links, pageLink(c, "next", "next_page", strconv.FormatInt(response.NextPage, 10)))
+	}
+	if response.PrevCursor != "" {
+		links = append(links, pageLink(c, "prev", "cursor", response.PrevCursor))
+	}
+	if len(links) > 0 {
+		c.Response().Header().Set("Link", strings.Join(links, ", "))
+	}
+}
+
+func pageLink(c echo.Context, rel string, param string, value string) string {
+	// a fresh copy of the query, echo caches the one QueryParams returns
+	query := c.Request().URL.Query()
+	query.Del("cursor")
+	query.Del("next_page")
+	query.Set(param, value)
+	link := fmt.Sprintf("%s://%s%s?%s", c.Scheme(), c.Request().Host, c.Request().URL.Path, query.Encode())
+	return fmt.Sprintf("<%s>; rel=\"%s\"", link, rel)
+}
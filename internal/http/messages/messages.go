@@ -0,0 +1,45 @@
+package messages
+
+import (
+	"net/http"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/messages"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler interface {
+	Post(c echo.Context) error
+}
+
+type messagesHandler struct {
+	messagesService messages.Service
+}
+
+func New(messagesService messages.Service) Handler {
+	return &messagesHandler{
+		messagesService,
+	}
+}
+
+func (h *messagesHandler) Post(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var messageRequest domain.MessageRequest
+	if err := c.Bind(&messageRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	message, err := h.messagesService.Post(ctx, c.Param("id"), userId, messageRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, message)
+}
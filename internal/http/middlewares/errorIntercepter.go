@@ -1,6 +1,8 @@
 package middlewares
 
 import (
+	"strconv"
+
 	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
 	"github.com/labstack/echo/v4"
 )
@@ -11,6 +13,9 @@ func ErrorIntercepter() echo.MiddlewareFunc {
 			err := next(c)
 			if err != nil {
 				res := exceptions.HandleExceptions(err)
+				if res.RetryAfter > 0 {
+					c.Response().Header().Set("Retry-After", strconv.Itoa(res.RetryAfter))
+				}
 				return c.JSON(res.Code, res)
 			}
 			return err
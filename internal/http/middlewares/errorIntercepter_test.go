@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/labstack/echo/v4"
+)
+
+func TestErrorIntercepterSetsRetryAfter(t *testing.T) {
+	cases := map[string]struct {
+		err        error
+		code       int
+		retryAfter string
+	}{
+		"rate limited": {exceptions.NewWithRetryAfter(exceptions.ErrChannelRateLimited, 1500*time.Millisecond), http.StatusTooManyRequests, "2"},
+		"slow mode":    {exceptions.NewWithRetryAfter(exceptions.ErrSlowModeActive, 30*time.Second), http.StatusTooManyRequests, "30"},
+		"not found":    {exceptions.New(exceptions.ErrChannelNotFound, nil), http.StatusNotFound, ""},
+	}
+	for name, c := range cases {
+		e := echo.New()
+		recorder := httptest.NewRecorder()
+		context := e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), recorder)
+		handler := ErrorIntercepter()(func(echo.Context) error { return c.err })
+
+		if err := handler(context); err != nil {
+			t.Fatal(err)
+		}
+		if recorder.Code != c.code {
+			t.Errorf("%s: responded %d, want %d", name, recorder.Code, c.code)
+		}
+		if got := recorder.Header().Get("Retry-After"); got != c.retryAfter {
+			t.Errorf("%s: Retry-After %q, want %q", name, got, c.retryAfter)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package recommendations
+
+import (
+	"net/http"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/recommendations"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler interface {
+	Recommend(c echo.Context) error
+}
+
+type recommendationsHandler struct {
+	recommendationsService recommendations.Service
+}
+
+func New(recommendationsService recommendations.Service) Handler {
+	return &recommendationsHandler{
+		recommendationsService,
+	}
+}
+
+func (h *recommendationsHandler) Recommend(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryParams helpers.QueryParams
+	err := helpers.BindQueryParams(c, &queryParams)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	channels, err := h.recommendationsService.Recommend(ctx, queryParams)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, channels)
+}
@@ -0,0 +1,108 @@
+package reports
+
+import (
+	"net/http"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/reports"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler interface {
+	Create(c echo.Context) error
+	List(c echo.Context) error
+	Assign(c echo.Context) error
+	Resolve(c echo.Context) error
+}
+
+type reportsHandler struct {
+	reportsService reports.Service
+}
+
+func New(reportsService reports.Service) Handler {
+	return &reportsHandler{
+		reportsService,
+	}
+}
+
+func (h *reportsHandler) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var reportRequest domain.ReportRequest
+	if err := c.Bind(&reportRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	report, err := h.reportsService.Create(ctx, c.Param("id"), userId, reportRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, report)
+}
+
+func (h *reportsHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryParams helpers.QueryParams
+	err := helpers.BindQueryParams(c, &queryParams)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	reports, err := h.reportsService.List(ctx, queryParams)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, reports)
+}
+
+func (h *reportsHandler) Assign(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var assignRequest domain.ReportAssignRequest
+	if err := c.Bind(&assignRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	err := h.reportsService.Assign(ctx, c.Param("id"), userId, assignRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *reportsHandler) Resolve(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var resolveRequest domain.ReportResolveRequest
+	if err := c.Bind(&resolveRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	err := h.reportsService.Resolve(ctx, c.Param("id"), userId, resolveRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
@@ -14,10 +14,33 @@ func SetupRouter(dependencies *config.Dependencies) *echo.Echo {
 
 	v1 := e.Group("/v1")
 	v1.POST("/channels", dependencies.Handler.Create, middlewares.ErrorIntercepter())
+	v1.GET("/channels/discover", dependencies.Handler.Discover, middlewares.ErrorIntercepter())
+	v1.GET("/channels/nearby", dependencies.Handler.Nearby, middlewares.ErrorIntercepter())
+	v1.GET("/channels/recommended", dependencies.RecommendationHandler.Recommend, middlewares.ErrorIntercepter())
 	v1.GET("/channels/:id", dependencies.Handler.Get, middlewares.ErrorIntercepter())
 	v1.GET("/channels", dependencies.Handler.List, middlewares.ErrorIntercepter())
 	v1.PATCH("/channels/:id", dependencies.Handler.Update, middlewares.ErrorIntercepter())
 	v1.DELETE("/channels/:id", dependencies.Handler.Delete, middlewares.ErrorIntercepter())
+	v1.POST("/channels/:id/lock", dependencies.Handler.Lock, middlewares.ErrorIntercepter())
+	v1.DELETE("/channels/:id/lock", dependencies.Handler.Unlock, middlewares.ErrorIntercepter())
+	v1.POST("/channels/:id/members/:member_id/timeout", dependencies.Handler.Timeout, middlewares.ErrorIntercepter())
+	v1.POST("/channels/:id/members/:member_id/kick", dependencies.Handler.Kick, middlewares.ErrorIntercepter())
+	v1.GET("/channels/:id/denunciated-members", dependencies.Handler.ListDenunciatedMembers, middlewares.ErrorIntercepter())
+	v1.GET("/channels/:id/audit", dependencies.AuditHandler.List, middlewares.ErrorIntercepter())
+	v1.POST("/channels/:id/messages", dependencies.MessageHandler.Post, middlewares.ErrorIntercepter())
+	v1.POST("/channels/:id/reports", dependencies.ReportHandler.Create, middlewares.ErrorIntercepter())
+	v1.POST("/channels/:id/webhooks", dependencies.WebhookHandler.Create, middlewares.ErrorIntercepter())
+	v1.GET("/channels/:id/webhooks", dependencies.WebhookHandler.List, middlewares.ErrorIntercepter())
+	v1.PATCH("/channels/:id/webhooks/:webhook_id", dependencies.WebhookHandler.Update, middlewares.ErrorIntercepter())
+	v1.DELETE("/channels/:id/webhooks/:webhook_id", dependencies.WebhookHandler.Delete, middlewares.ErrorIntercepter())
+	v1.GET("/channels/:id/webhooks/:webhook_id/deliveries", dependencies.WebhookHandler.ListDeliveries, middlewares.ErrorIntercepter())
+
+	v1.GET("/schemas", dependencies.SchemaHandler.List, middlewares.ErrorIntercepter())
+	v1.GET("/schemas/:type/:version", dependencies.SchemaHandler.Get, middlewares.ErrorIntercepter())
+
+	v1.GET("/reports", dependencies.ReportHandler.List, middlewares.ErrorIntercepter())
+	v1.PATCH("/reports/:id/assign", dependencies.ReportHandler.Assign, middlewares.ErrorIntercepter())
+	v1.PATCH("/reports/:id/resolve", dependencies.ReportHandler.Resolve, middlewares.ErrorIntercepter())
 
 	return e
 }
@@ -0,0 +1,45 @@
+package schemas
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/labstack/echo/v4"
+)
+
+const SCHEMA_CONTENT_TYPE = "application/schema+json"
+
+type Handler interface {
+	List(c echo.Context) error
+	Get(c echo.Context) error
+}
+
+type schemasHandler struct {
+	schemaRegistry events.SchemaRegistry
+}
+
+func New(schemaRegistry events.SchemaRegistry) Handler {
+	return &schemasHandler{
+		schemaRegistry,
+	}
+}
+
+func (h *schemasHandler) List(c echo.Context) error {
+	return c.JSON(http.StatusOK, events.SchemasResponse{Schemas: h.schemaRegistry.List()})
+}
+
+func (h *schemasHandler) Get(c echo.Context) error {
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil {
+		return exceptions.New(exceptions.ErrSchemaNotFound, err)
+	}
+
+	schema, ok := h.schemaRegistry.Get(c.Param("type"), version)
+	if !ok {
+		return exceptions.New(exceptions.ErrSchemaNotFound, nil)
+	}
+
+	return c.Blob(http.StatusOK, SCHEMA_CONTENT_TYPE, schema)
+}
@@ -0,0 +1,120 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/webhooks"
+	"github.com/labstack/echo/v4"
+)
+
+type Handler interface {
+	Create(c echo.Context) error
+	List(c echo.Context) error
+	Update(c echo.Context) error
+	Delete(c echo.Context) error
+	ListDeliveries(c echo.Context) error
+}
+
+type webhooksHandler struct {
+	webhooksService webhooks.Service
+}
+
+func New(webhooksService webhooks.Service) Handler {
+	return &webhooksHandler{
+		webhooksService,
+	}
+}
+
+func (h *webhooksHandler) Create(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var webhookRequest domain.WebhookRequest
+	if err := c.Bind(&webhookRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	webhook, err := h.webhooksService.Create(ctx, c.Param("id"), userId, webhookRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusCreated, webhook)
+}
+
+func (h *webhooksHandler) List(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	webhooks, err := h.webhooksService.List(ctx, c.Param("id"), userId)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *webhooksHandler) Update(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	var patchRequest domain.WebhookPatchRequest
+	if err := c.Bind(&patchRequest); err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	webhook, err := h.webhooksService.Update(ctx, c.Param("id"), c.Param("webhook_id"), userId, patchRequest)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, webhook)
+}
+
+func (h *webhooksHandler) Delete(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	userId := c.Request().Header.Get("user_id")
+	if userId == "" {
+		return exceptions.New(exceptions.ErrHeaderUserIdIsReq, nil)
+	}
+
+	err := h.webhooksService.Delete(ctx, c.Param("id"), c.Param("webhook_id"), userId)
+	if err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *webhooksHandler) ListDeliveries(c echo.Context) error {
+	ctx := c.Request().Context()
+
+	var queryParams helpers.QueryParams
+	err := helpers.BindQueryParams(c, &queryParams)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidPayload, err)
+	}
+
+	deliveries, err := h.webhooksService.ListDeliveries(ctx, c.Param("id"), c.Param("webhook_id"), queryParams)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, deliveries)
+}
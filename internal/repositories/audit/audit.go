@@ -0,0 +1,78 @@
+package audit
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const AUDIT_COLLECTION = "audit_events"
+
+type Repository interface {
+	Create(ctx context.Context, event *domain.AuditEvent) error
+	List(ctx context.Context, channelId primitive.ObjectID, actorId *primitive.ObjectID, action string, limit int64, offset int64) ([]*domain.AuditEvent, error)
+	LastAdminsChange(ctx context.Context, channelId primitive.ObjectID) (*domain.AuditEvent, error)
+}
+
+type AuditRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &AuditRepository{db}
+}
+
+func (h *AuditRepository) Create(ctx context.Context, event *domain.AuditEvent) error {
+	err := event.Create(ctx, h.db, AUDIT_COLLECTION, event)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// List returns the events of the channel, newest first.
+func (h *AuditRepository) List(ctx context.Context, channelId primitive.ObjectID, actorId *primitive.ObjectID, action string, limit int64, offset int64) ([]*domain.AuditEvent, error) {
+	var events []*domain.AuditEvent = make([]*domain.AuditEvent, 0)
+	filter := bson.M{"channel_id": channelId}
+	if actorId != nil {
+		filter["actor_id"] = *actorId
+	}
+	if action != "" {
+		filter["action"] = action
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip(offset * limit)
+	err := mongorm.List(ctx, h.db, AUDIT_COLLECTION, filter, &events, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return events, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return events, nil
+}
+
+// LastAdminsChange returns the latest event of the channel touching its admins, nil when there is none.
+func (h *AuditRepository) LastAdminsChange(ctx context.Context, channelId primitive.ObjectID) (*domain.AuditEvent, error) {
+	event := &domain.AuditEvent{}
+	filter := bson.M{"channel_id": channelId, "changes.admins": bson.M{"$exists": true}}
+	opts := options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	err := event.Read(ctx, h.db, AUDIT_COLLECTION, filter, event, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return event, nil
+}
@@ -3,6 +3,9 @@ package channels
 import (
 	"context"
 	"errors"
+	"math"
+	"slices"
+	"time"
 
 	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
 	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
@@ -14,17 +17,31 @@ import (
 )
 
 const (
-	CHANNEL_COLLECTION = "channels"
-	USER_COLLECTION    = "users"
+	CHANNEL_COLLECTION      = "channels"
+	USER_PROFILE_COLLECTION = "user_profiles"
+	RESUME_TOKEN_COLLECTION = "resume_tokens"
+	LEASE_COLLECTION        = "leases"
+	MESSAGE_COLLECTION      = "messages"
 )
 
 type Repository interface {
 	Create(ctx context.Context, Channel *domain.Channel) (*domain.Channel, error)
 	Get(ctx context.Context, id primitive.ObjectID) (*domain.Channel, error)
-	List(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, limit int64, offset int64) ([]*domain.Channel, error)
-	Aggregate(ctx context.Context, userIds []primitive.ObjectID, headerUserId primitive.ObjectID) ([]*domain.ChannelWithMembers, error)
+	GetWithProjection(ctx context.Context, id primitive.ObjectID, projection bson.M) (*domain.Channel, error)
+	List(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string, page domain.Page, projection bson.M) ([]*domain.Channel, bool, error)
+	Count(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string) (int64, error)
+	Aggregate(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string, page domain.Page, membersLimit int64, projection bson.M) ([]*domain.ChannelWithMembers, bool, error)
 	Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error
 	Delete(ctx context.Context, id primitive.ObjectID) error
+	ListWithRetention(ctx context.Context) ([]*domain.Channel, error)
+	ListByMember(ctx context.Context, userId primitive.ObjectID) ([]*domain.Channel, error)
+	Discover(ctx context.Context, userId primitive.ObjectID, categories []primitive.ObjectID, limit int64, offset int64) ([]*domain.DiscoveredChannel, bool, error)
+	Nearby(ctx context.Context, userId primitive.ObjectID, point *domain.GeoPoint, radius float64, limit int64, offset int64) ([]*domain.NearbyChannel, bool, error)
+	Recommend(ctx context.Context, query domain.RecommendationQuery) ([]*domain.RecommendedChannel, bool, error)
+	CountRecommendable(ctx context.Context, query domain.RecommendationQuery, limit int64) (int64, error)
+	CoMembers(ctx context.Context, userId primitive.ObjectID) ([]primitive.ObjectID, error)
+	EnsureIndexes(ctx context.Context) error
+	Watch(ctx context.Context, handler mongorm.ChangeHandler)
 }
 
 type ChannelRepository struct {
@@ -36,6 +53,7 @@ func New(db *mongo.Database) Repository {
 }
 
 func (h *ChannelRepository) Create(ctx context.Context, channel *domain.Channel) (*domain.Channel, error) {
+	channel.MemberCount = len(channel.Members)
 	filter := bson.M{"members": bson.M{"$all": channel.Members}}
 	err := channel.Read(ctx, h.db, CHANNEL_COLLECTION, filter, channel)
 	if err != nil {
@@ -64,73 +82,173 @@ func (h *ChannelRepository) Get(ctx context.Context, id primitive.ObjectID) (*do
 	return Channel, nil
 }
 
-func (h *ChannelRepository) List(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, limit int64, offset int64) ([]*domain.Channel, error) {
+// GetWithProjection reads only the projected fields of the channel, a nil projection reads them all.
+func (h *ChannelRepository) GetWithProjection(ctx context.Context, id primitive.ObjectID, projection bson.M) (*domain.Channel, error) {
+	Channel := &domain.Channel{}
+	opts := options.FindOne()
+	if projection != nil {
+		opts.SetProjection(projection)
+	}
+	err := Channel.Read(ctx, h.db, CHANNEL_COLLECTION, bson.M{"_id": id}, Channel, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, exceptions.New(exceptions.ErrChannelNotFound, err)
+		}
+
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return Channel, nil
+}
+
+// List returns a page of channels in the page sort, or by relevance when searching, and whether
+// more channels follow in the direction the page was read. A nil projection reads every field.
+func (h *ChannelRepository) List(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string, page domain.Page, projection bson.M) ([]*domain.Channel, bool, error) {
 	var channels []*domain.Channel = make([]*domain.Channel, 0)
-	var filter bson.M
-	if len(channelIds) > 0 {
-		filter = bson.M{"_id": bson.M{"$in": channelIds}}
+	filter := listFilter(channelIds, userIds, headerUserId)
+	// one more channel than the limit tells whether another page follows
+	opts := options.Find().SetLimit(page.Limit + 1)
+	if projection != nil {
+		opts.SetProjection(projection)
 	}
-	if len(userIds) > 0 {
-		if filter == nil {
-			filter = bson.M{"members": bson.M{"$all": userIds}}
-		} else {
-			filter["members"] = bson.M{"$all": userIds}
+	if search != "" {
+		filter["$text"] = bson.M{"$search": search}
+		score := bson.M{"$meta": "textScore"}
+		if projection == nil {
+			// a projection of the score alone still reads every field
+			projection = bson.M{}
 		}
+		projection["score"] = score
+		// equal scores are ordered by id so pages neither skip nor repeat channels
+		opts.SetProjection(projection).SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).SetSkip(page.Offset * page.Limit)
 	} else {
-		if filter == nil {
-			filter = bson.M{"members": headerUserId}
+		if page.Cursor != nil {
+			filter["$or"] = keysetFilter(page.Sort, page.Cursor.Values, page.IsBackward())
 		} else {
-			filter["members"] = headerUserId
+			opts.SetSkip(page.Offset * page.Limit)
 		}
+		opts.SetSort(sortDocument(page.Sort, page.IsBackward()))
 	}
-	err := mongorm.List(ctx, h.db, CHANNEL_COLLECTION, filter, &channels, options.Find().SetLimit(limit).SetSkip(offset*limit))
+	err := mongorm.List(ctx, h.db, CHANNEL_COLLECTION, filter, &channels, opts)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return channels, nil
+			return channels, false, nil
 		}
-		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+		return nil, false, exceptions.New(exceptions.ErrDatabaseFailure, err)
 	}
-	return channels, nil
-}
 
-func (h *ChannelRepository) Aggregate(ctx context.Context, userIds []primitive.ObjectID, headerUserId primitive.ObjectID) ([]*domain.ChannelWithMembers, error) {
-	var channels []*domain.ChannelWithMembers = make([]*domain.ChannelWithMembers, 0)
-	var filter bson.M = bson.M{}
+	hasMore := int64(len(channels)) > page.Limit
+	if hasMore {
+		channels = channels[:page.Limit]
+	}
+	if page.IsBackward() {
+		slices.Reverse(channels)
+	}
+	return channels, hasMore, nil
+}
 
+// listFilter matches the requested channels among those shared by the users, or among the
+// channels of the caller when no users are sent.
+func listFilter(channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID) bson.M {
+	filter := bson.M{}
+	if len(channelIds) > 0 {
+		filter["_id"] = bson.M{"$in": channelIds}
+	}
 	if len(userIds) > 0 {
-		filter["members"] = bson.M{"$in": userIds}
+		filter["members"] = bson.M{"$all": userIds}
 	} else {
 		filter["members"] = headerUserId
 	}
+	return filter
+}
+
+// Count returns how many channels List and Aggregate match across all pages.
+func (h *ChannelRepository) Count(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string) (int64, error) {
+	filter := listFilter(channelIds, userIds, headerUserId)
+	if search != "" {
+		filter["$text"] = bson.M{"$search": search}
+	}
+	count, err := mongorm.Count(ctx, h.db, CHANNEL_COLLECTION, filter)
+	if err != nil {
+		return 0, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return count, nil
+}
+
+// Aggregate returns a page of channels like List, with up to membersLimit members and every admin
+// expanded to their profiles, and whether more channels follow in the direction the page was read.
+// Only the members and admins left by the projection are expanded.
+func (h *ChannelRepository) Aggregate(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string, page domain.Page, membersLimit int64, projection bson.M) ([]*domain.ChannelWithMembers, bool, error) {
+	var channels []*domain.ChannelWithMembers = make([]*domain.ChannelWithMembers, 0)
+	filter := listFilter(channelIds, userIds, headerUserId)
 
 	pipeline := mongo.Pipeline{
 		{{Key: "$match", Value: filter}},
-		{{Key: "$lookup", Value: bson.M{
-			"from":         USER_COLLECTION,
-			"localField":   "members",
-			"foreignField": "_id",
-			"as":           "members",
+	}
+	if search != "" {
+		// $text is only allowed in the first $match stage of the pipeline
+		filter["$text"] = bson.M{"$search": search}
+		pipeline = append(pipeline,
+			bson.D{{Key: "$addFields", Value: bson.M{"score": bson.M{"$meta": "textScore"}}}},
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "_id", Value: 1}}}},
+			bson.D{{Key: "$skip", Value: page.Offset * page.Limit}},
+		)
+	} else {
+		if page.Cursor != nil {
+			filter["$or"] = keysetFilter(page.Sort, page.Cursor.Values, page.IsBackward())
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sortDocument(page.Sort, page.IsBackward())}})
+		if page.Cursor == nil {
+			pipeline = append(pipeline, bson.D{{Key: "$skip", Value: page.Offset * page.Limit}})
+		}
+	}
+	// the page is cut before the lookups so only its channels are expanded
+	pipeline = append(pipeline, mongo.Pipeline{
+		{{Key: "$limit", Value: page.Limit + 1}},
+		{{Key: "$addFields", Value: bson.M{
+			"members_total": bson.M{"$size": "$members"},
+			"members":       bson.M{"$slice": bson.A{"$members", membersLimit}},
 		}}},
-		{{Key: "$lookup", Value: bson.M{
-			"from":         USER_COLLECTION,
-			"localField":   "admins",
+	}...)
+	if projection != nil {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: projection}})
+	}
+	for _, field := range []string{"members", "admins"} {
+		if _, ok := projection[field]; projection != nil && !ok {
+			continue
+		}
+		pipeline = append(pipeline, bson.D{{Key: "$lookup", Value: bson.M{
+			"from":         USER_PROFILE_COLLECTION,
+			"localField":   field,
 			"foreignField": "_id",
-			"as":           "admins",
-		}}},
+			"as":           field,
+		}}})
 	}
 
 	err := mongorm.Aggregate(ctx, h.db, CHANNEL_COLLECTION, pipeline, &channels)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
-			return channels, nil
+			return channels, false, nil
 		}
-		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+		return nil, false, exceptions.New(exceptions.ErrDatabaseFailure, err)
 	}
 
-	return channels, nil
+	hasMore := int64(len(channels)) > page.Limit
+	if hasMore {
+		channels = channels[:page.Limit]
+	}
+	if page.IsBackward() {
+		slices.Reverse(channels)
+	}
+	return channels, hasMore, nil
 }
 
 func (h *ChannelRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	// the member count is stored so channels can be sorted by it with an index
+	if set, ok := fields["$set"].(bson.M); ok {
+		if members, ok := set["members"].([]primitive.ObjectID); ok {
+			set["member_count"] = len(members)
+		}
+	}
 	Channel := &domain.Channel{}
 	err := Channel.Update(ctx, h.db, CHANNEL_COLLECTION, bson.M{"_id": id}, fields, options.FindOneAndUpdate().SetReturnDocument(options.After))
 	if err != nil {
@@ -153,3 +271,367 @@ func (h *ChannelRepository) Delete(ctx context.Context, id primitive.ObjectID) e
 	}
 	return nil
 }
+
+func (h *ChannelRepository) ListWithRetention(ctx context.Context) ([]*domain.Channel, error) {
+	var channels []*domain.Channel = make([]*domain.Channel, 0)
+	filter := bson.M{"retention_days": bson.M{"$gt": 0}}
+	err := mongorm.List(ctx, h.db, CHANNEL_COLLECTION, filter, &channels)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return channels, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return channels, nil
+}
+
+// ListByMember returns every channel the user is a member or an admin of.
+func (h *ChannelRepository) ListByMember(ctx context.Context, userId primitive.ObjectID) ([]*domain.Channel, error) {
+	var channels []*domain.Channel = make([]*domain.Channel, 0)
+	filter := bson.M{"$or": []bson.M{{"members": userId}, {"admins": userId}}}
+	err := mongorm.List(ctx, h.db, CHANNEL_COLLECTION, filter, &channels)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return channels, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return channels, nil
+}
+
+// Discover returns a page of the public channels the user is not in, sharing the most categories
+// with the user first, and whether another page follows.
+func (h *ChannelRepository) Discover(ctx context.Context, userId primitive.ObjectID, categories []primitive.ObjectID, limit int64, offset int64) ([]*domain.DiscoveredChannel, bool, error) {
+	var channels []*domain.DiscoveredChannel = make([]*domain.DiscoveredChannel, 0)
+	if categories == nil {
+		categories = make([]primitive.ObjectID, 0)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"visibility": domain.VISIBILITY_PUBLIC,
+			"members":    bson.M{"$ne": userId},
+		}}},
+		{{Key: "$addFields", Value: bson.M{
+			"category_overlap": bson.M{"$size": bson.M{"$setIntersection": bson.A{
+				bson.M{"$ifNull": bson.A{"$categories", bson.A{}}},
+				categories,
+			}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "category_overlap", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$skip", Value: offset * limit}},
+		{{Key: "$limit", Value: limit + 1}},
+	}
+
+	err := mongorm.Aggregate(ctx, h.db, CHANNEL_COLLECTION, pipeline, &channels)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return channels, false, nil
+		}
+		return nil, false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	hasMore := int64(len(channels)) > limit
+	if hasMore {
+		channels = channels[:limit]
+	}
+	return channels, hasMore, nil
+}
+
+// Nearby returns a page of the public channels within radius meters of the point, closest first,
+// and whether another page follows.
+func (h *ChannelRepository) Nearby(ctx context.Context, userId primitive.ObjectID, point *domain.GeoPoint, radius float64, limit int64, offset int64) ([]*domain.NearbyChannel, bool, error) {
+	var channels []*domain.NearbyChannel = make([]*domain.NearbyChannel, 0)
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.M{
+			"near":          point,
+			"distanceField": "distance",
+			"maxDistance":   radius,
+			"spherical":     true,
+			"query": bson.M{
+				"visibility": domain.VISIBILITY_PUBLIC,
+				"members":    bson.M{"$ne": userId},
+			},
+		}}},
+		{{Key: "$skip", Value: offset * limit}},
+		{{Key: "$limit", Value: limit + 1}},
+	}
+
+	err := mongorm.Aggregate(ctx, h.db, CHANNEL_COLLECTION, pipeline, &channels)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return channels, false, nil
+		}
+		return nil, false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	hasMore := int64(len(channels)) > limit
+	if hasMore {
+		channels = channels[:limit]
+	}
+	return channels, hasMore, nil
+}
+
+// Recommend ranks the candidates of recommendationFilter. They are first scored on the category
+// overlap, proximity and co-members, the best query.Pool of them get their last message looked up
+// to add the activity, then the page is read from the final ranking along with one more channel
+// telling whether there is a next page.
+func (h *ChannelRepository) Recommend(ctx context.Context, query domain.RecommendationQuery) ([]*domain.RecommendedChannel, bool, error) {
+	var channels []*domain.RecommendedChannel = make([]*domain.RecommendedChannel, 0)
+	categories := query.Categories
+	if categories == nil {
+		categories = make([]primitive.ObjectID, 0)
+	}
+	coMembers := query.CoMembers
+	if coMembers == nil {
+		coMembers = make([]primitive.ObjectID, 0)
+	}
+
+	var distance interface{}
+	if query.Location != nil {
+		distance = bson.M{"$cond": bson.A{
+			bson.M{"$eq": bson.A{bson.M{"$type": "$location.coordinates"}, "array"}},
+			distanceExpression(query.Location),
+			nil,
+		}}
+	}
+	coMemberCount := bson.M{"$size": bson.M{"$setIntersection": bson.A{bson.M{"$ifNull": bson.A{"$members", bson.A{}}}, coMembers}}}
+	categoryOverlap := bson.M{"$size": bson.M{"$setIntersection": bson.A{bson.M{"$ifNull": bson.A{"$categories", bson.A{}}}, categories}}}
+
+	baseScore := bson.A{
+		bson.M{"$multiply": bson.A{query.Weights.CoMembers, bson.M{"$divide": bson.A{"$co_member_count", bson.M{"$add": bson.A{"$co_member_count", 1}}}}}},
+		bson.M{"$cond": bson.A{
+			bson.M{"$gte": bson.A{bson.M{"$ifNull": bson.A{"$distance", -1}}, 0}},
+			bson.M{"$multiply": bson.A{query.Weights.Proximity, bson.M{"$divide": bson.A{
+				domain.PROXIMITY_HALF_DISTANCE,
+				bson.M{"$add": bson.A{domain.PROXIMITY_HALF_DISTANCE, "$distance"}},
+			}}}},
+			0,
+		}},
+	}
+	if len(categories) > 0 {
+		baseScore = append(baseScore, bson.M{"$multiply": bson.A{query.Weights.Category, bson.M{"$divide": bson.A{"$category_overlap", len(categories)}}}})
+	}
+	idleDays := bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{query.Now, "$last_message_at"}}, float64(24 * time.Hour / time.Millisecond)}}
+	activity := bson.M{"$cond": bson.A{
+		bson.M{"$ifNull": bson.A{"$last_message_at", false}},
+		bson.M{"$multiply": bson.A{query.Weights.Activity, bson.M{"$pow": bson.A{0.5, bson.M{"$divide": bson.A{idleDays, domain.ACTIVITY_HALF_LIFE_DAYS}}}}}},
+		0,
+	}}
+
+	filter, matchesSignals := recommendationFilter(query)
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	if !matchesSignals {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$sort", Value: bson.D{{Key: "member_count", Value: -1}, {Key: "_id", Value: 1}}}},
+			bson.D{{Key: "$limit", Value: query.Pool}},
+		)
+	}
+	pipeline = append(pipeline, mongo.Pipeline{
+		{{Key: "$addFields", Value: bson.M{
+			"category_overlap": categoryOverlap,
+			"co_member_count":  coMemberCount,
+			"distance":         distance,
+		}}},
+		{{Key: "$addFields", Value: bson.M{"base_score": bson.M{"$add": baseScore}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "base_score", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$limit", Value: query.Pool}},
+		{{Key: "$lookup", Value: bson.M{
+			"from": MESSAGE_COLLECTION,
+			"let":  bson.M{"channel_id": "$_id"},
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: bson.M{"$expr": bson.M{"$eq": bson.A{"$channel_id", "$$channel_id"}}}}},
+				{{Key: "$sort", Value: bson.M{"created_at": -1}}},
+				{{Key: "$limit", Value: 1}},
+				{{Key: "$project", Value: bson.M{"created_at": 1}}},
+			},
+			"as": "last_message",
+		}}},
+		{{Key: "$addFields", Value: bson.M{"last_message_at": bson.M{"$first": "$last_message.created_at"}}}},
+		{{Key: "$addFields", Value: bson.M{"recommendation_score": bson.M{"$add": bson.A{"$base_score", activity}}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "recommendation_score", Value: -1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$skip", Value: query.Offset * query.Limit}},
+		{{Key: "$limit", Value: query.Limit + 1}},
+		{{Key: "$project", Value: bson.M{"base_score": 0, "last_message": 0}}},
+	}...)
+
+	err := mongorm.Aggregate(ctx, h.db, CHANNEL_COLLECTION, pipeline, &channels)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return channels, false, nil
+		}
+		return nil, false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	hasMore := int64(len(channels)) > query.Limit
+	if hasMore {
+		channels = channels[:query.Limit]
+	}
+	return channels, hasMore, nil
+}
+
+// CountRecommendable counts the candidates Recommend ranks, up to limit.
+func (h *ChannelRepository) CountRecommendable(ctx context.Context, query domain.RecommendationQuery, limit int64) (int64, error) {
+	filter, _ := recommendationFilter(query)
+	count, err := mongorm.Count(ctx, h.db, CHANNEL_COLLECTION, filter, options.Count().SetLimit(limit))
+	if err != nil {
+		return 0, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return count, nil
+}
+
+// recommendationFilter matches the public channels the user is not in that share a category, a
+// co-member or a location within RECOMMENDATION_RADIUS_METERS with the user, each read from an index.
+// A user with none of them matches every public channel, and is recommended the most populated ones,
+// telling so with false.
+func recommendationFilter(query domain.RecommendationQuery) (bson.M, bool) {
+	filter := bson.M{
+		"visibility": domain.VISIBILITY_PUBLIC,
+		"members":    bson.M{"$ne": query.UserId},
+	}
+	signals := make([]bson.M, 0, 3)
+	if len(query.Categories) > 0 {
+		signals = append(signals, bson.M{"categories": bson.M{"$in": query.Categories}})
+	}
+	if len(query.CoMembers) > 0 {
+		signals = append(signals, bson.M{"members": bson.M{"$in": query.CoMembers}})
+	}
+	if query.Location != nil {
+		signals = append(signals, bson.M{"location": bson.M{"$geoWithin": bson.M{
+			"$centerSphere": bson.A{query.Location.Coordinates, float64(domain.RECOMMENDATION_RADIUS_METERS) / domain.EARTH_RADIUS_METERS},
+		}}})
+	}
+	if len(signals) == 0 {
+		return filter, false
+	}
+	filter["$or"] = signals
+	return filter, true
+}
+
+// distanceExpression computes the great-circle distance in meters between the point and the
+// location of the channel, the same way as domain.GeoPoint.DistanceTo.
+func distanceExpression(point *domain.GeoPoint) bson.M {
+	lng1 := point.Coordinates[0] * math.Pi / 180
+	lat1 := point.Coordinates[1] * math.Pi / 180
+	lng2 := bson.M{"$degreesToRadians": bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 0}}}
+	lat2 := bson.M{"$degreesToRadians": bson.M{"$arrayElemAt": bson.A{"$location.coordinates", 1}}}
+	halfSin := func(a interface{}, b float64) bson.M {
+		return bson.M{"$pow": bson.A{bson.M{"$sin": bson.M{"$divide": bson.A{bson.M{"$subtract": bson.A{a, b}}, 2}}}, 2}}
+	}
+
+	a := bson.M{"$add": bson.A{
+		halfSin(lat2, lat1),
+		bson.M{"$multiply": bson.A{math.Cos(lat1), bson.M{"$cos": lat2}, halfSin(lng2, lng1)}},
+	}}
+	return bson.M{"$multiply": bson.A{
+		2 * domain.EARTH_RADIUS_METERS,
+		bson.M{"$asin": bson.M{"$sqrt": bson.M{"$min": bson.A{a, 1}}}},
+	}}
+}
+
+// CoMembers returns the distinct members of every channel the user belongs to, excluding the user.
+func (h *ChannelRepository) CoMembers(ctx context.Context, userId primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var results []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"members": userId}}},
+		{{Key: "$unwind", Value: "$members"}},
+		{{Key: "$match", Value: bson.M{"members": bson.M{"$ne": userId}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$members"}}},
+	}
+
+	err := mongorm.Aggregate(ctx, h.db, CHANNEL_COLLECTION, pipeline, &results)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	coMembers := make([]primitive.ObjectID, 0, len(results))
+	for _, result := range results {
+		coMembers = append(coMembers, result.ID)
+	}
+	return coMembers, nil
+}
+
+func (h *ChannelRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "location", Value: "2dsphere"}}},
+		{Keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}}},
+		// the recommendation candidates, matched on their categories or on the most populated public channels
+		{Keys: bson.D{{Key: "categories", Value: 1}}},
+		{Keys: bson.D{{Key: "visibility", Value: 1}, {Key: "member_count", Value: -1}, {Key: "_id", Value: 1}}},
+	}
+	// one index per sort field and per indexed sort on several fields, each walked in either direction.
+	// The listings always match members by equality, $all included, so the sort is read from the index
+	// with the channel_ids filter applied to its trailing _id.
+	for _, field := range domain.CHANNEL_SORT_FIELDS {
+		indexes = append(indexes, sortIndex([]domain.SortKey{{Field: field, Descending: true}, {Field: "_id", Descending: true}}))
+	}
+	for _, spec := range domain.CHANNEL_INDEXED_SORTS {
+		keys, err := domain.ParseChannelSort(spec)
+		if err != nil {
+			return err
+		}
+		indexes = append(indexes, sortIndex(keys))
+	}
+
+	err := mongorm.CreateIndexes(ctx, h.db, CHANNEL_COLLECTION, indexes)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	// channels created before the member count was stored
+	_, err = mongorm.UpdateMany(ctx, h.db, CHANNEL_COLLECTION,
+		bson.M{"member_count": bson.M{"$exists": false}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{"member_count": bson.M{"$size": bson.M{"$ifNull": bson.A{"$members", bson.A{}}}}}}}},
+	)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// Watch feeds the changes of the channels collection to the handler until ctx is cancelled,
+// including the ones made outside the service. Only one instance of the service watches at a time.
+func (h *ChannelRepository) Watch(ctx context.Context, handler mongorm.ChangeHandler) {
+	mongorm.NewWatcher(h.db, CHANNEL_COLLECTION, RESUME_TOKEN_COLLECTION, LEASE_COLLECTION, handler).Start(ctx)
+}
+
+// keysetFilter matches the documents after the values in the sort, or before them when backward.
+// For keys k1, k2 it is k1 past v1, or k1 equal to v1 and k2 past v2.
+func keysetFilter(keys []domain.SortKey, values []interface{}, backward bool) bson.A {
+	clauses := bson.A{}
+	for i, key := range keys {
+		clause := bson.M{}
+		for j := 0; j < i; j++ {
+			clause[keys[j].Field] = values[j]
+		}
+		operator := "$gt"
+		if key.Descending != backward {
+			operator = "$lt"
+		}
+		clause[key.Field] = bson.M{operator: values[i]}
+		clauses = append(clauses, clause)
+	}
+	return clauses
+}
+
+// sortIndex is the index serving the sort of the listings of a member.
+func sortIndex(keys []domain.SortKey) mongo.IndexModel {
+	return mongo.IndexModel{Keys: append(bson.D{{Key: "members", Value: 1}}, sortDocument(keys, false)...)}
+}
+
+// sortDocument renders the sort, reversed when reading backward.
+func sortDocument(keys []domain.SortKey, backward bool) bson.D {
+	sort := bson.D{}
+	for _, key := range keys {
+		direction := 1
+		if key.Descending != backward {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: key.Field, Value: direction})
+	}
+	return sort
+}
@@ -0,0 +1,151 @@
+package deliveries
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const DELIVERY_COLLECTION = "webhook_deliveries"
+
+type Repository interface {
+	Enqueue(ctx context.Context, deliveries ...*domain.WebhookDelivery) error
+	ClaimDue(ctx context.Context, now time.Time, claimUntil time.Time) (*domain.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id primitive.ObjectID, responseStatus int) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, status string, nextAttemptAt time.Time, responseStatus int, cause error) error
+	Postpone(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time) error
+	Resume(ctx context.Context, webhookId primitive.ObjectID, now time.Time) error
+	List(ctx context.Context, webhookId primitive.ObjectID, status string, limit int64, offset int64) ([]*domain.WebhookDelivery, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type DeliveryRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &DeliveryRepository{db}
+}
+
+// Enqueue stores the deliveries, an event already enqueued for a webhook is skipped
+// so republishing the same event does not send it twice.
+func (h *DeliveryRepository) Enqueue(ctx context.Context, deliveries ...*domain.WebhookDelivery) error {
+	for _, delivery := range deliveries {
+		err := delivery.Create(ctx, h.db, DELIVERY_COLLECTION, delivery)
+		if err != nil {
+			if mongo.IsDuplicateKeyError(err) {
+				continue
+			}
+			return exceptions.New(exceptions.ErrDatabaseFailure, err)
+		}
+	}
+	return nil
+}
+
+// ClaimDue takes the pending delivery due the longest for this instance to send, or returns nil when
+// none is due. The claim moves its next attempt to claimUntil atomically, so the other instances skip
+// it until then and it is retried if this instance stops before recording the outcome.
+func (h *DeliveryRepository) ClaimDue(ctx context.Context, now time.Time, claimUntil time.Time) (*domain.WebhookDelivery, error) {
+	filter := bson.M{"status": domain.DELIVERY_STATUS_PENDING, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_attempt_at": claimUntil}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	delivery := &domain.WebhookDelivery{}
+	err := mongorm.FindOneAndUpdate(ctx, h.db, DELIVERY_COLLECTION, filter, update, delivery, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return delivery, nil
+}
+
+func (h *DeliveryRepository) MarkDelivered(ctx context.Context, id primitive.ObjectID, responseStatus int) error {
+	return h.update(ctx, id, bson.M{
+		"$set": bson.M{"status": domain.DELIVERY_STATUS_DELIVERED, "response_status": responseStatus, "delivered_at": time.Now()},
+		"$inc": bson.M{"attempts": 1},
+	})
+}
+
+// MarkFailed records a failed attempt, status stays pending while the delivery will be retried.
+func (h *DeliveryRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, status string, nextAttemptAt time.Time, responseStatus int, cause error) error {
+	return h.update(ctx, id, bson.M{
+		"$set": bson.M{"status": status, "next_attempt_at": nextAttemptAt, "response_status": responseStatus, "last_error": cause.Error()},
+		"$inc": bson.M{"attempts": 1},
+	})
+}
+
+// Postpone moves the next attempt of a pending delivery without counting an attempt.
+func (h *DeliveryRepository) Postpone(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time) error {
+	return h.update(ctx, id, bson.M{"$set": bson.M{"next_attempt_at": nextAttemptAt}})
+}
+
+// Resume makes the pending deliveries of the webhook due now, they were postponed while it was disabled.
+func (h *DeliveryRepository) Resume(ctx context.Context, webhookId primitive.ObjectID, now time.Time) error {
+	filter := bson.M{"webhook_id": webhookId, "status": domain.DELIVERY_STATUS_PENDING}
+	_, err := mongorm.UpdateMany(ctx, h.db, DELIVERY_COLLECTION, filter, bson.M{"$set": bson.M{"next_attempt_at": now}})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *DeliveryRepository) List(ctx context.Context, webhookId primitive.ObjectID, status string, limit int64, offset int64) ([]*domain.WebhookDelivery, error) {
+	filter := bson.M{"webhook_id": webhookId}
+	if status != "" {
+		filter["status"] = status
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}).
+		SetLimit(limit).
+		SetSkip(offset * limit)
+	return h.list(ctx, filter, opts)
+}
+
+func (h *DeliveryRepository) EnsureIndexes(ctx context.Context) error {
+	err := mongorm.CreateIndexes(ctx, h.db, DELIVERY_COLLECTION, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "webhook_id", Value: 1}, {Key: "event_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}},
+		},
+	})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *DeliveryRepository) list(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]*domain.WebhookDelivery, error) {
+	var deliveries []*domain.WebhookDelivery = make([]*domain.WebhookDelivery, 0)
+	err := mongorm.List(ctx, h.db, DELIVERY_COLLECTION, filter, &deliveries, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return deliveries, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return deliveries, nil
+}
+
+func (h *DeliveryRepository) update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	delivery := &domain.WebhookDelivery{}
+	err := delivery.Update(ctx, h.db, DELIVERY_COLLECTION, bson.M{"_id": id}, update)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package inbox
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const PROCESSED_EVENT_COLLECTION = "processed_events"
+
+type Repository interface {
+	IsProcessed(ctx context.Context, eventId string) (bool, error)
+	MarkProcessed(ctx context.Context, eventId string, eventType string) error
+}
+
+type InboxRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &InboxRepository{db}
+}
+
+func (h *InboxRepository) IsProcessed(ctx context.Context, eventId string) (bool, error) {
+	err := h.db.Collection(PROCESSED_EVENT_COLLECTION).FindOne(ctx, bson.M{"_id": eventId}).Err()
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return false, nil
+		}
+		return false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return true, nil
+}
+
+func (h *InboxRepository) MarkProcessed(ctx context.Context, eventId string, eventType string) error {
+	event := domain.ProcessedEvent{
+		ID:          eventId,
+		Type:        eventType,
+		ProcessedAt: time.Now(),
+	}
+	_, err := h.db.Collection(PROCESSED_EVENT_COLLECTION).ReplaceOne(ctx, bson.M{"_id": eventId}, event, options.Replace().SetUpsert(true))
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	MESSAGE_COLLECTION     = "messages"
+	RATE_WINDOW_COLLECTION = "message_rate_windows"
+	SLOW_MODE_COLLECTION   = "message_slow_modes"
+)
+
+type Repository interface {
+	Create(ctx context.Context, message *domain.Message) (*domain.Message, error)
+	Get(ctx context.Context, id primitive.ObjectID) (*domain.Message, error)
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	AcquireSlowMode(ctx context.Context, channelId primitive.ObjectID, memberId primitive.ObjectID, now time.Time, nextPostAt time.Time) (bool, time.Time, error)
+	AcquireRate(ctx context.Context, channelId primitive.ObjectID, windowStart time.Time, windowEnd time.Time, limit int) (bool, error)
+	DeleteOlderThan(ctx context.Context, channelId primitive.ObjectID, cutoff time.Time) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+}
+
+type MessageRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &MessageRepository{db}
+}
+
+func (h *MessageRepository) Create(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+	err := message.Create(ctx, h.db, MESSAGE_COLLECTION, message)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return message, nil
+}
+
+// AcquireSlowMode lets the member post in the channel unless its slot is still running, and moves the slot
+// to nextPostAt when it does. Otherwise it returns false with the instant the member may post again. The
+// slot is only matched once over, so a concurrent post collides with it on the unique index instead.
+func (h *MessageRepository) AcquireSlowMode(ctx context.Context, channelId primitive.ObjectID, memberId primitive.ObjectID, now time.Time, nextPostAt time.Time) (bool, time.Time, error) {
+	slot := &domain.SlowModeSlot{}
+	err := slot.Read(ctx, h.db, SLOW_MODE_COLLECTION, bson.M{"channel_id": channelId, "member_id": memberId}, slot)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return false, time.Time{}, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	if err == nil && slot.NextPostAt.After(now) {
+		return false, slot.NextPostAt, nil
+	}
+
+	filter := bson.M{"channel_id": channelId, "member_id": memberId, "next_post_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_post_at": nextPostAt}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = mongorm.FindOneAndUpdate(ctx, h.db, SLOW_MODE_COLLECTION, filter, update, slot, opts)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nextPostAt, nil
+		}
+		return false, time.Time{}, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return true, nextPostAt, nil
+}
+
+// AcquireRate counts a message in the window of the channel unless limit messages were already
+// counted in it, and tells whether it was counted. The window is only matched while below the limit,
+// so once the limit is reached the upsert collides with it on the unique index instead of counting,
+// which keeps concurrent posts from going past the limit.
+func (h *MessageRepository) AcquireRate(ctx context.Context, channelId primitive.ObjectID, windowStart time.Time, windowEnd time.Time, limit int) (bool, error) {
+	window := &domain.RateWindow{}
+	filter := bson.M{"channel_id": channelId, "window_start": windowStart, "count": bson.M{"$lt": limit}}
+	update := bson.M{
+		"$set":         bson.M{},
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"expires_at": windowEnd},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := window.Update(ctx, h.db, RATE_WINDOW_COLLECTION, filter, update, opts)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return true, nil
+}
+
+// Get returns the message, or nil when it does not exist or was purged.
+func (h *MessageRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Message, error) {
+	return h.findOne(ctx, bson.M{"_id": id}, nil)
+}
+
+// Delete removes the message, a message already purged is not an error.
+func (h *MessageRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	message := &domain.Message{}
+	err := message.Delete(ctx, h.db, MESSAGE_COLLECTION, bson.M{"_id": id})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *MessageRepository) findOne(ctx context.Context, filter bson.M, opts *options.FindOneOptions) (*domain.Message, error) {
+	message := &domain.Message{}
+	err := message.Read(ctx, h.db, MESSAGE_COLLECTION, filter, message, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return message, nil
+}
+
+func (h *MessageRepository) DeleteOlderThan(ctx context.Context, channelId primitive.ObjectID, cutoff time.Time) (int64, error) {
+	filter := bson.M{
+		"channel_id": channelId,
+		"created_at": bson.M{"$lt": cutoff},
+	}
+	deleted, err := mongorm.DeleteMany(ctx, h.db, MESSAGE_COLLECTION, filter)
+	if err != nil {
+		return 0, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return deleted, nil
+}
+
+func (h *MessageRepository) EnsureIndexes(ctx context.Context) error {
+	err := mongorm.CreateIndexes(ctx, h.db, MESSAGE_COLLECTION, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "channel_id", Value: 1}, {Key: "created_at", Value: 1}}},
+	})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	err = mongorm.CreateIndexes(ctx, h.db, RATE_WINDOW_COLLECTION, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "channel_id", Value: 1}, {Key: "window_start", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "expires_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	err = mongorm.CreateIndexes(ctx, h.db, SLOW_MODE_COLLECTION, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "channel_id", Value: 1}, {Key: "member_id", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			Keys:    bson.D{{Key: "next_post_at", Value: 1}},
+			Options: options.Index().SetExpireAfterSeconds(0),
+		},
+	})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
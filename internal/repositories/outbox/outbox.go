@@ -0,0 +1,170 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	OUTBOX_COLLECTION = "outbox"
+	LEASE_COLLECTION  = "leases"
+	RELAY_LEASE       = "outbox_relay"
+)
+
+type Repository interface {
+	WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Add(ctx context.Context, events ...events.Event) error
+	ListPending(ctx context.Context, now time.Time, limit int64) ([]*domain.OutboxEntry, error)
+	MarkDelivered(ctx context.Context, id primitive.ObjectID) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time, cause error) error
+	MarkDead(ctx context.Context, id primitive.ObjectID, cause error) error
+	DeleteDelivered(ctx context.Context, before time.Time) (int64, error)
+	EnsureIndexes(ctx context.Context) error
+	HoldLease(ctx context.Context, fn func(ctx context.Context) error) (bool, error)
+}
+
+type OutboxRepository struct {
+	db    *mongo.Database
+	lease *mongorm.Lease
+}
+
+func New(db *mongo.Database) Repository {
+	return &OutboxRepository{db, mongorm.NewLease(db, LEASE_COLLECTION, RELAY_LEASE)}
+}
+
+func (h *OutboxRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := mongorm.WithTransaction(ctx, h.db, fn)
+	if err != nil {
+		var customErr *exceptions.Error
+		if errors.As(err, &customErr) {
+			return err
+		}
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// Add stores the events in order, ctx must come from WithTransaction for them to be written atomically with the change.
+func (h *OutboxRepository) Add(ctx context.Context, events ...events.Event) error {
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return exceptions.New(exceptions.ErrDatabaseFailure, err)
+		}
+
+		entry := &domain.OutboxEntry{
+			EventId:       event.ID,
+			EventType:     event.Type,
+			ChannelId:     event.ChannelId,
+			Payload:       string(payload),
+			NextAttemptAt: event.OccurredAt,
+		}
+		err = entry.Create(ctx, h.db, OUTBOX_COLLECTION, entry)
+		if err != nil {
+			return exceptions.New(exceptions.ErrDatabaseFailure, err)
+		}
+	}
+	return nil
+}
+
+// ListPending returns the entries due by now in the order they were written. The channels with an
+// entry waiting for its retry are left out entirely, so their later entries are not delivered ahead of it.
+func (h *OutboxRepository) ListPending(ctx context.Context, now time.Time, limit int64) ([]*domain.OutboxEntry, error) {
+	var entries []*domain.OutboxEntry = make([]*domain.OutboxEntry, 0)
+	blocked, err := mongorm.Distinct(ctx, h.db, OUTBOX_COLLECTION, "channel_id", bson.M{
+		"delivered_at":    bson.M{"$exists": false},
+		"dead_at":         bson.M{"$exists": false},
+		"next_attempt_at": bson.M{"$gt": now},
+	})
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	filter := bson.M{
+		"delivered_at": bson.M{"$exists": false},
+		"dead_at":      bson.M{"$exists": false},
+		"$or": bson.A{
+			bson.M{"next_attempt_at": bson.M{"$lte": now}},
+			bson.M{"next_attempt_at": bson.M{"$exists": false}},
+		},
+	}
+	if len(blocked) > 0 {
+		filter["channel_id"] = bson.M{"$nin": blocked}
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+	err = mongorm.List(ctx, h.db, OUTBOX_COLLECTION, filter, &entries, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return entries, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return entries, nil
+}
+
+func (h *OutboxRepository) MarkDelivered(ctx context.Context, id primitive.ObjectID) error {
+	return h.update(ctx, id, bson.M{"$set": bson.M{"delivered_at": time.Now()}})
+}
+
+func (h *OutboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time, cause error) error {
+	return h.update(ctx, id, bson.M{
+		"$set": bson.M{"next_attempt_at": nextAttemptAt, "last_error": cause.Error()},
+		"$inc": bson.M{"attempts": 1},
+	})
+}
+
+// MarkDead parks the entry after its last attempt, it is no longer listed as pending.
+func (h *OutboxRepository) MarkDead(ctx context.Context, id primitive.ObjectID, cause error) error {
+	return h.update(ctx, id, bson.M{
+		"$set": bson.M{"dead_at": time.Now(), "last_error": cause.Error()},
+		"$inc": bson.M{"attempts": 1},
+	})
+}
+
+func (h *OutboxRepository) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	deleted, err := mongorm.DeleteMany(ctx, h.db, OUTBOX_COLLECTION, bson.M{"delivered_at": bson.M{"$lt": before}})
+	if err != nil {
+		return 0, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return deleted, nil
+}
+
+func (h *OutboxRepository) EnsureIndexes(ctx context.Context) error {
+	indexes := []mongo.IndexModel{
+		{Keys: bson.D{{Key: "delivered_at", Value: 1}, {Key: "next_attempt_at", Value: 1}, {Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}},
+	}
+
+	err := mongorm.CreateIndexes(ctx, h.db, OUTBOX_COLLECTION, indexes)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// HoldLease runs fn while this instance is the one relaying the outbox, so that the entries of a
+// channel are published by a single relay in order. It reports false when another instance relays.
+func (h *OutboxRepository) HoldLease(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	return h.lease.Hold(ctx, fn)
+}
+
+func (h *OutboxRepository) update(ctx context.Context, id primitive.ObjectID, update bson.M) error {
+	entry := &domain.OutboxEntry{}
+	err := entry.Update(ctx, h.db, OUTBOX_COLLECTION, bson.M{"_id": id}, update)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package profiles
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const USER_PROFILE_COLLECTION = "user_profiles"
+
+type Repository interface {
+	Get(ctx context.Context, id primitive.ObjectID) (*domain.UserProfile, error)
+	Upsert(ctx context.Context, profile *domain.UserProfile) error
+	MarkDenunciated(ctx context.Context, id primitive.ObjectID, version time.Time) error
+	Delete(ctx context.Context, id primitive.ObjectID, version time.Time) error
+	ListDenunciated(ctx context.Context, ids []primitive.ObjectID) ([]*domain.UserProfile, error)
+	ListExistingIds(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error)
+}
+
+type ProfileRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &ProfileRepository{db}
+}
+
+func (h *ProfileRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.UserProfile, error) {
+	profile := &domain.UserProfile{}
+	filter := bson.M{"_id": id, "deleted": bson.M{"$ne": true}}
+	err := h.db.Collection(USER_PROFILE_COLLECTION).FindOne(ctx, filter).Decode(profile)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, exceptions.New(exceptions.ErrUserNotFound, err)
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return profile, nil
+}
+
+// Upsert stores the profile unless a newer version is already stored, or a tombstone of the same
+// version or newer.
+func (h *ProfileRepository) Upsert(ctx context.Context, profile *domain.UserProfile) error {
+	filter := bson.M{"_id": profile.ID, "$or": versionFilter(profile.Version)}
+	_, err := h.db.Collection(USER_PROFILE_COLLECTION).ReplaceOne(ctx, filter, profile, options.Replace().SetUpsert(true))
+	if err != nil {
+		// the filter missed because of a newer version, inserting then collides on the _id
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// MarkDenunciated flags the profile unless a newer version is already stored, a profile not
+// projected yet is created with the id only and filled by the next upsert.
+func (h *ProfileRepository) MarkDenunciated(ctx context.Context, id primitive.ObjectID, version time.Time) error {
+	filter := bson.M{"_id": id, "$or": versionFilter(version)}
+	update := bson.M{"$set": bson.M{"is_denunciated": true, "version": version}}
+	_, err := h.db.Collection(USER_PROFILE_COLLECTION).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// Delete replaces the profile with a tombstone unless a newer version is already stored.
+func (h *ProfileRepository) Delete(ctx context.Context, id primitive.ObjectID, version time.Time) error {
+	filter := bson.M{"_id": id, "version": bson.M{"$lte": version}}
+	tombstone := &domain.UserProfile{ID: id, Deleted: true, Version: version}
+	_, err := h.db.Collection(USER_PROFILE_COLLECTION).ReplaceOne(ctx, filter, tombstone, options.Replace().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil
+		}
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *ProfileRepository) ListDenunciated(ctx context.Context, ids []primitive.ObjectID) ([]*domain.UserProfile, error) {
+	var profiles []*domain.UserProfile = make([]*domain.UserProfile, 0)
+	filter := bson.M{
+		"_id":            bson.M{"$in": ids},
+		"is_denunciated": true,
+		"deleted":        bson.M{"$ne": true},
+	}
+	err := mongorm.List(ctx, h.db, USER_PROFILE_COLLECTION, filter, &profiles)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return profiles, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return profiles, nil
+}
+
+func (h *ProfileRepository) ListExistingIds(ctx context.Context, ids []primitive.ObjectID) ([]primitive.ObjectID, error) {
+	var profiles []*domain.UserProfile = make([]*domain.UserProfile, 0)
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	filter := bson.M{"_id": bson.M{"$in": ids}, "deleted": bson.M{"$ne": true}}
+	err := mongorm.List(ctx, h.db, USER_PROFILE_COLLECTION, filter, &profiles, opts)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	existing := make([]primitive.ObjectID, 0, len(profiles))
+	for _, profile := range profiles {
+		existing = append(existing, profile.ID)
+	}
+	return existing, nil
+}
+
+// versionFilter matches the stored profiles a change of the given version applies to: older
+// profiles, and tombstones strictly older since a deletion wins over a change of the same time.
+func versionFilter(version time.Time) []bson.M {
+	return []bson.M{
+		{"deleted": bson.M{"$ne": true}, "version": bson.M{"$lte": version}},
+		{"deleted": true, "version": bson.M{"$lt": version}},
+	}
+}
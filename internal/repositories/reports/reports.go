@@ -0,0 +1,132 @@
+package reports
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const REPORT_COLLECTION = "reports"
+
+type Repository interface {
+	Create(ctx context.Context, report *domain.Report) (*domain.Report, error)
+	Get(ctx context.Context, id primitive.ObjectID) (*domain.Report, error)
+	List(ctx context.Context, status string, targetType string, limit int64, offset int64) ([]*domain.Report, error)
+	Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error
+	CountOpenByTarget(ctx context.Context, targetType string, targetId primitive.ObjectID) (int64, error)
+	FlagByTarget(ctx context.Context, targetType string, targetId primitive.ObjectID) error
+}
+
+type ReportRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &ReportRepository{db}
+}
+
+// Create stores the report unless the reporter already has an unresolved report for the same target.
+func (h *ReportRepository) Create(ctx context.Context, report *domain.Report) (*domain.Report, error) {
+	existing := &domain.Report{}
+	filter := bson.M{
+		"target_type": report.TargetType,
+		"target_id":   report.TargetId,
+		"reporter_id": report.ReporterId,
+		"status":      bson.M{"$ne": domain.REPORT_STATUS_RESOLVED},
+	}
+	err := existing.Read(ctx, h.db, REPORT_COLLECTION, filter, existing)
+	if err == nil {
+		return nil, exceptions.New(exceptions.ErrReportAlreadyExists, nil)
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	err = report.Create(ctx, h.db, REPORT_COLLECTION, report)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return report, nil
+}
+
+func (h *ReportRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Report, error) {
+	report := &domain.Report{}
+	err := report.Read(ctx, h.db, REPORT_COLLECTION, bson.M{"_id": id}, report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, exceptions.New(exceptions.ErrReportNotFound, err)
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return report, nil
+}
+
+// List returns the moderation queue, flagged reports first and then oldest first.
+func (h *ReportRepository) List(ctx context.Context, status string, targetType string, limit int64, offset int64) ([]*domain.Report, error) {
+	var reports []*domain.Report = make([]*domain.Report, 0)
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if targetType != "" {
+		filter["target_type"] = targetType
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "flagged", Value: -1}, {Key: "created_at", Value: 1}}).
+		SetLimit(limit).
+		SetSkip(offset * limit)
+	err := mongorm.List(ctx, h.db, REPORT_COLLECTION, filter, &reports, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return reports, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return reports, nil
+}
+
+func (h *ReportRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	report := &domain.Report{}
+	err := report.Update(ctx, h.db, REPORT_COLLECTION, bson.M{"_id": id}, bson.M{"$set": fields}, options.FindOneAndUpdate().SetReturnDocument(options.After))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return exceptions.New(exceptions.ErrReportNotFound, err)
+		}
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *ReportRepository) CountOpenByTarget(ctx context.Context, targetType string, targetId primitive.ObjectID) (int64, error) {
+	filter := bson.M{
+		"target_type": targetType,
+		"target_id":   targetId,
+		"status":      bson.M{"$ne": domain.REPORT_STATUS_RESOLVED},
+	}
+	count, err := mongorm.Count(ctx, h.db, REPORT_COLLECTION, filter)
+	if err != nil {
+		return 0, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return count, nil
+}
+
+func (h *ReportRepository) FlagByTarget(ctx context.Context, targetType string, targetId primitive.ObjectID) error {
+	filter := bson.M{
+		"target_type": targetType,
+		"target_id":   targetId,
+		"status":      bson.M{"$ne": domain.REPORT_STATUS_RESOLVED},
+	}
+	_, err := mongorm.UpdateMany(ctx, h.db, REPORT_COLLECTION, filter, bson.M{"$set": bson.M{"flagged": true}})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
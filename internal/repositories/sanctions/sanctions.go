@@ -0,0 +1,75 @@
+package sanctions
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const SANCTION_COLLECTION = "sanctions"
+
+type Repository interface {
+	Create(ctx context.Context, sanction *domain.Sanction) (*domain.Sanction, error)
+	ActiveTimeout(ctx context.Context, channelId primitive.ObjectID, memberId primitive.ObjectID, now time.Time) (*domain.Sanction, error)
+	ListActive(ctx context.Context, channelIds []primitive.ObjectID, now time.Time) ([]*domain.Sanction, error)
+}
+
+type SanctionRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &SanctionRepository{db}
+}
+
+func (h *SanctionRepository) Create(ctx context.Context, sanction *domain.Sanction) (*domain.Sanction, error) {
+	err := sanction.Create(ctx, h.db, SANCTION_COLLECTION, sanction)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return sanction, nil
+}
+
+// ActiveTimeout returns the timeout of the member lasting the longest, or nil when the member can post.
+func (h *SanctionRepository) ActiveTimeout(ctx context.Context, channelId primitive.ObjectID, memberId primitive.ObjectID, now time.Time) (*domain.Sanction, error) {
+	sanction := &domain.Sanction{}
+	filter := bson.M{
+		"channel_id": channelId,
+		"member_id":  memberId,
+		"type":       domain.SANCTION_TIMEOUT,
+		"until":      bson.M{"$gt": now},
+	}
+	err := sanction.Read(ctx, h.db, SANCTION_COLLECTION, filter, sanction, options.FindOne().SetSort(bson.M{"until": -1}))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return sanction, nil
+}
+
+func (h *SanctionRepository) ListActive(ctx context.Context, channelIds []primitive.ObjectID, now time.Time) ([]*domain.Sanction, error) {
+	var sanctions []*domain.Sanction = make([]*domain.Sanction, 0)
+	filter := bson.M{
+		"channel_id": bson.M{"$in": channelIds},
+		"type":       domain.SANCTION_TIMEOUT,
+		"until":      bson.M{"$gt": now},
+	}
+	err := mongorm.List(ctx, h.db, SANCTION_COLLECTION, filter, &sanctions)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return sanctions, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return sanctions, nil
+}
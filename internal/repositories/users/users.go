@@ -0,0 +1,57 @@
+package users
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const USER_COLLECTION = "users"
+
+type Repository interface {
+	Get(ctx context.Context, id primitive.ObjectID) (*domain.User, error)
+	ListAfter(ctx context.Context, afterId primitive.ObjectID, limit int64) ([]*domain.User, error)
+}
+
+type UserRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &UserRepository{db}
+}
+
+func (h *UserRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.User, error) {
+	user := &domain.User{}
+	err := user.Read(ctx, h.db, USER_COLLECTION, bson.M{"_id": id}, user)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, exceptions.New(exceptions.ErrUserNotFound, err)
+		}
+
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return user, nil
+}
+
+// ListAfter returns the users following afterId in id order, to go through the whole collection.
+func (h *UserRepository) ListAfter(ctx context.Context, afterId primitive.ObjectID, limit int64) ([]*domain.User, error) {
+	var users []*domain.User = make([]*domain.User, 0)
+	filter := bson.M{"_id": bson.M{"$gt": afterId}}
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(limit)
+	err := mongorm.List(ctx, h.db, USER_COLLECTION, filter, &users, opts)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return users, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return users, nil
+}
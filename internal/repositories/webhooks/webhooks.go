@@ -0,0 +1,125 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const WEBHOOK_COLLECTION = "webhooks"
+
+type Repository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error)
+	Get(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error)
+	ListByChannel(ctx context.Context, channelId primitive.ObjectID) ([]*domain.Webhook, error)
+	ListSubscribed(ctx context.Context, channelId primitive.ObjectID, eventType string) ([]*domain.Webhook, error)
+	Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error
+	Delete(ctx context.Context, id primitive.ObjectID) error
+	RecordSuccess(ctx context.Context, id primitive.ObjectID) error
+	RecordFailure(ctx context.Context, id primitive.ObjectID, disableAfter int) (bool, error)
+}
+
+type WebhookRepository struct {
+	db *mongo.Database
+}
+
+func New(db *mongo.Database) Repository {
+	return &WebhookRepository{db}
+}
+
+func (h *WebhookRepository) Create(ctx context.Context, webhook *domain.Webhook) (*domain.Webhook, error) {
+	err := webhook.Create(ctx, h.db, WEBHOOK_COLLECTION, webhook)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return webhook, nil
+}
+
+func (h *WebhookRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	webhook := &domain.Webhook{}
+	err := webhook.Read(ctx, h.db, WEBHOOK_COLLECTION, bson.M{"_id": id}, webhook)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, exceptions.New(exceptions.ErrWebhookNotFound, err)
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return webhook, nil
+}
+
+func (h *WebhookRepository) ListByChannel(ctx context.Context, channelId primitive.ObjectID) ([]*domain.Webhook, error) {
+	return h.list(ctx, bson.M{"channel_id": channelId})
+}
+
+// ListSubscribed returns the enabled webhooks of the channel subscribed to the event type.
+func (h *WebhookRepository) ListSubscribed(ctx context.Context, channelId primitive.ObjectID, eventType string) ([]*domain.Webhook, error) {
+	return h.list(ctx, bson.M{"channel_id": channelId, "enabled": true, "event_types": eventType})
+}
+
+func (h *WebhookRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	webhook := &domain.Webhook{}
+	err := webhook.Update(ctx, h.db, WEBHOOK_COLLECTION, bson.M{"_id": id}, fields)
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *WebhookRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	webhook := &domain.Webhook{}
+	err := webhook.Delete(ctx, h.db, WEBHOOK_COLLECTION, bson.M{"_id": id})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return exceptions.New(exceptions.ErrWebhookNotFound, err)
+		}
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+func (h *WebhookRepository) RecordSuccess(ctx context.Context, id primitive.ObjectID) error {
+	_, err := mongorm.UpdateMany(ctx, h.db, WEBHOOK_COLLECTION,
+		bson.M{"_id": id, "consecutive_failures": bson.M{"$gt": 0}},
+		bson.M{"$set": bson.M{"consecutive_failures": 0}})
+	if err != nil {
+		return exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return nil
+}
+
+// RecordFailure counts a failed attempt and disables the webhook once disableAfter attempts
+// failed in a row. It reports whether this call disabled the webhook.
+func (h *WebhookRepository) RecordFailure(ctx context.Context, id primitive.ObjectID, disableAfter int) (bool, error) {
+	_, err := mongorm.UpdateMany(ctx, h.db, WEBHOOK_COLLECTION, bson.M{"_id": id}, bson.M{"$inc": bson.M{"consecutive_failures": 1}})
+	if err != nil {
+		return false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+
+	disabled, err := mongorm.UpdateMany(ctx, h.db, WEBHOOK_COLLECTION,
+		bson.M{"_id": id, "enabled": true, "consecutive_failures": bson.M{"$gte": disableAfter}},
+		bson.M{"$set": bson.M{"enabled": false, "disabled_at": time.Now()}})
+	if err != nil {
+		return false, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return disabled > 0, nil
+}
+
+func (h *WebhookRepository) list(ctx context.Context, filter bson.M) ([]*domain.Webhook, error) {
+	var webhooks []*domain.Webhook = make([]*domain.Webhook, 0)
+	err := mongorm.List(ctx, h.db, WEBHOOK_COLLECTION, filter, &webhooks, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return webhooks, nil
+		}
+		return nil, exceptions.New(exceptions.ErrDatabaseFailure, err)
+	}
+	return webhooks, nil
+}
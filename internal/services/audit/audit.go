@@ -0,0 +1,182 @@
+package audit
+
+import (
+	"context"
+	"slices"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/audit"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service interface {
+	Record(ctx context.Context, actorId string, before *domain.Channel, after *domain.Channel) error
+	RecordSanction(ctx context.Context, sanction *domain.Sanction) error
+	List(ctx context.Context, id string, queryParams helpers.QueryParams) (*domain.AuditResponse, error)
+}
+
+type AuditService struct {
+	auditRepository   audit.Repository
+	channelRepository channels.Repository
+	staffIds          map[string]bool
+}
+
+func New(auditRepository audit.Repository, channelRepository channels.Repository, staffIds []string) Service {
+	staff := make(map[string]bool, len(staffIds))
+	for _, staffId := range staffIds {
+		staff[staffId] = true
+	}
+	return &AuditService{
+		auditRepository,
+		channelRepository,
+		staff,
+	}
+}
+
+// Record stores the events describing the transition of a channel from before to after.
+// Membership changes are recorded apart from the other fields so they can be filtered on.
+// ctx must come from the transaction of the mutation, so the change is not persisted without its events.
+func (h *AuditService) Record(ctx context.Context, actorId string, before *domain.Channel, after *domain.Channel) error {
+	changes := domain.DiffChannels(before, after)
+
+	var channelId primitive.ObjectID
+	var action string
+	switch {
+	case before == nil:
+		channelId = after.ID
+		action = domain.AUDIT_ACTION_CREATED
+	case after == nil:
+		channelId = before.ID
+		action = domain.AUDIT_ACTION_DELETED
+	default:
+		channelId = after.ID
+		action = domain.AUDIT_ACTION_UPDATED
+	}
+
+	events := make([]*domain.AuditEvent, 0, 2)
+	if action == domain.AUDIT_ACTION_UPDATED {
+		membershipChanges := make(map[string]domain.FieldChange)
+		for field, change := range changes {
+			if domain.IsMembershipChange(field) {
+				membershipChanges[field] = change
+				delete(changes, field)
+			}
+		}
+		if len(membershipChanges) > 0 {
+			events = append(events, &domain.AuditEvent{Action: domain.AUDIT_ACTION_MEMBERSHIP_CHANGED, Changes: membershipChanges})
+		}
+		if len(changes) > 0 {
+			events = append(events, &domain.AuditEvent{Action: domain.AUDIT_ACTION_UPDATED, Changes: changes})
+		}
+	} else {
+		events = append(events, &domain.AuditEvent{Action: action, Changes: changes})
+	}
+
+	var parsedActorId *primitive.ObjectID
+	if id, err := primitive.ObjectIDFromHex(actorId); err == nil {
+		parsedActorId = &id
+	}
+	for _, event := range events {
+		event.ChannelId = channelId
+		event.ActorId = parsedActorId
+		if err := h.auditRepository.Create(ctx, event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordSanction stores the event of the sanction, within the transaction of ctx like Record.
+func (h *AuditService) RecordSanction(ctx context.Context, sanction *domain.Sanction) error {
+	event := &domain.AuditEvent{
+		ChannelId: sanction.ChannelId,
+		ActorId:   &sanction.IssuedBy,
+		TargetId:  &sanction.MemberId,
+		Reason:    sanction.Reason,
+		Changes:   map[string]domain.FieldChange{},
+	}
+	switch sanction.Type {
+	case domain.SANCTION_TIMEOUT:
+		event.Action = domain.AUDIT_ACTION_MEMBER_TIMED_OUT
+		event.Changes["timed_out_until"] = domain.FieldChange{Before: nil, After: sanction.Until}
+	case domain.SANCTION_KICK:
+		event.Action = domain.AUDIT_ACTION_MEMBER_KICKED
+	}
+
+	return h.auditRepository.Create(ctx, event)
+}
+
+func (h *AuditService) List(ctx context.Context, id string, queryParams helpers.QueryParams) (*domain.AuditResponse, error) {
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+	parsedHeaderUserId, err := primitive.ObjectIDFromHex(queryParams.HeaderUserId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	var actorId *primitive.ObjectID
+	if queryParams.Actor != "" {
+		parsedActorId, err := primitive.ObjectIDFromHex(queryParams.Actor)
+		if err != nil {
+			return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+		}
+		actorId = &parsedActorId
+	}
+	if queryParams.Action != "" && !domain.IsValidAuditAction(queryParams.Action) {
+		return nil, exceptions.New(exceptions.ErrInvalidAuditAction, nil)
+	}
+
+	err = h.authorize(ctx, parsedId, parsedHeaderUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := h.auditRepository.List(ctx, parsedId, actorId, queryParams.Action, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.AuditResponse{
+		Events: events,
+	}
+	if len(events) == int(queryParams.Limit) {
+		response.NextPage = queryParams.Offset + 1
+	}
+
+	return response, nil
+}
+
+// authorize lets staff read any audit and admins read the audit of their channels. The admins are
+// taken from the audit itself, so the audit of a deleted channel stays readable by its last admins.
+// Channels whose admins were never audited, created before auditing, fall back to the stored channel.
+func (h *AuditService) authorize(ctx context.Context, channelId primitive.ObjectID, userId primitive.ObjectID) error {
+	if h.staffIds[userId.Hex()] {
+		return nil
+	}
+
+	event, err := h.auditRepository.LastAdminsChange(ctx, channelId)
+	if err != nil {
+		return err
+	}
+	if event != nil {
+		admins, _ := event.AuditedAdmins()
+		if !slices.Contains(admins, userId) {
+			return exceptions.New(exceptions.ErrUserIsNotAdmin, nil)
+		}
+		return nil
+	}
+
+	channel, err := h.channelRepository.Get(ctx, channelId)
+	if err != nil {
+		return err
+	}
+	if !channel.IsAdmin(userId) {
+		return exceptions.New(exceptions.ErrUserIsNotAdmin, nil)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package changestream
+
+import (
+	"context"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/mongorm"
+)
+
+type Service interface {
+	Handle(ctx context.Context, change mongorm.ChangeEvent) error
+	Start(ctx context.Context)
+}
+
+type ChangeStreamService struct {
+	channelRepository channels.Repository
+	eventPublisher    events.EventPublisher
+	enabled           bool
+}
+
+func New(channelRepository channels.Repository, eventPublisher events.EventPublisher, enabled bool) Service {
+	return &ChangeStreamService{
+		channelRepository,
+		eventPublisher,
+		enabled,
+	}
+}
+
+// Handle publishes the events of a change made outside the service. The service writes its
+// channels in transactions along with their outbox events, so transactional changes are skipped
+// to avoid publishing them twice.
+func (h *ChangeStreamService) Handle(ctx context.Context, change mongorm.ChangeEvent) error {
+	if change.TxnNumber != nil {
+		return nil
+	}
+
+	pending, err := events.FromChange(change)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	return h.eventPublisher.Publish(ctx, pending...)
+}
+
+// Start watches the channels until ctx is cancelled, when enabled.
+func (h *ChangeStreamService) Start(ctx context.Context) {
+	if !h.enabled {
+		return
+	}
+	h.channelRepository.Watch(ctx, h.Handle)
+}
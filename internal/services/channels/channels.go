@@ -2,33 +2,72 @@ package channels
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
 
 	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/directory"
 	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
 	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
 	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/sanctions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/audit"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/contentpolicy"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/cursor"
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Service interface {
-	Create(ctx context.Context, request domain.ChannelRequest) (*domain.Channel, error)
-	Get(ctx context.Context, id string) (*domain.Channel, error)
+	Create(ctx context.Context, actorId string, request domain.ChannelRequest) (*domain.Channel, error)
+	Get(ctx context.Context, id string, fields string) (domain.ChannelResponseGeneral, error)
 	List(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error)
-	Update(ctx context.Context, id string, request domain.ChannelPatchRequest) error
-	Delete(ctx context.Context, id string) error
+	Update(ctx context.Context, id string, actorId string, request domain.ChannelPatchRequest) error
+	Delete(ctx context.Context, id string, actorId string) error
+	Discover(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error)
+	Nearby(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error)
+	ListDenunciatedMembers(ctx context.Context, id string, headerUserId string) (*domain.MembersResponse, error)
+	Lock(ctx context.Context, id string, actorId string, request domain.LockRequest) error
+	Unlock(ctx context.Context, id string, actorId string) error
+	Timeout(ctx context.Context, id string, memberId string, actorId string, request domain.TimeoutRequest) (*domain.Sanction, error)
+	Kick(ctx context.Context, id string, memberId string, actorId string, request domain.KickRequest) (*domain.Sanction, error)
+	RemoveUser(ctx context.Context, userId string) error
+	RemoveMember(ctx context.Context, id string, memberId string, actorId string) error
+	SetReportFlag(ctx context.Context, id string, actorId string, flagged bool) error
 }
 
 type ChannelService struct {
-	channelRepository channels.Repository
+	channelRepository  channels.Repository
+	denunciationPolicy string
+	contentPolicy      contentpolicy.Policy
+	auditService       audit.Service
+	sanctionRepository sanctions.Repository
+	outboxRepository   outbox.Repository
+	profileRepository  profiles.Repository
+	userDirectory      directory.UserDirectory
+	cursorSigner       *cursor.Signer
 }
 
-func New(channelRepository channels.Repository) Service {
+func New(channelRepository channels.Repository, denunciationPolicy string, contentPolicy contentpolicy.Policy, auditService audit.Service, sanctionRepository sanctions.Repository, outboxRepository outbox.Repository, profileRepository profiles.Repository, userDirectory directory.UserDirectory, cursorSigner *cursor.Signer) Service {
 	return &ChannelService{
 		channelRepository,
+		denunciationPolicy,
+		contentPolicy,
+		auditService,
+		sanctionRepository,
+		outboxRepository,
+		profileRepository,
+		userDirectory,
+		cursorSigner,
 	}
 }
 
-func (h *ChannelService) Create(ctx context.Context, request domain.ChannelRequest) (*domain.Channel, error) {
+func (h *ChannelService) Create(ctx context.Context, actorId string, request domain.ChannelRequest) (*domain.Channel, error) {
 	err := request.Validate()
 	if err != nil {
 		return nil, err
@@ -36,15 +75,55 @@ func (h *ChannelService) Create(ctx context.Context, request domain.ChannelReque
 
 	Channel := request.ToChannel()
 
-	return h.channelRepository.Create(ctx, Channel)
+	err = h.checkUsersExist(ctx, append(append([]primitive.ObjectID{}, Channel.Members...), Channel.Admins...))
+	if err != nil {
+		return nil, err
+	}
+
+	flagged, err := h.applyContentPolicy(&Channel.Name, &Channel.Description)
+	if err != nil {
+		return nil, err
+	}
+	if flagged {
+		Channel.Flagged = true
+		Channel.FlagReasons = []string{domain.FLAG_REASON_CONTENT_POLICY}
+	}
+
+	err = h.inTransaction(ctx, func(ctx context.Context) ([]events.Event, error) {
+		created, err := h.channelRepository.Create(ctx, Channel)
+		if err != nil {
+			return nil, err
+		}
+		Channel = created
+		err = h.auditService.Record(ctx, actorId, nil, created)
+		if err != nil {
+			return nil, err
+		}
+		return events.FromChannelChange(actorId, nil, created), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return Channel, nil
 }
 
-func (h *ChannelService) Get(ctx context.Context, id string) (*domain.Channel, error) {
+func (h *ChannelService) Get(ctx context.Context, id string, fields string) (domain.ChannelResponseGeneral, error) {
 	parsedId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return nil, exceptions.New(exceptions.ErrInvalidID, err)
 	}
-	return h.channelRepository.Get(ctx, parsedId)
+	selected, err := domain.ParseChannelFields(fields, false)
+	if err != nil {
+		return nil, err
+	}
+	channel, err := h.channelRepository.GetWithProjection(ctx, parsedId, selected.Projection(nil))
+	if err != nil {
+		return nil, err
+	}
+
+	channel.RefreshLock(time.Now())
+	return selected.Select(channel)
 }
 
 func (h *ChannelService) List(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error) {
@@ -58,59 +137,690 @@ func (h *ChannelService) List(ctx context.Context, queryParams helpers.QueryPara
 		return nil, err
 	}
 
+	parsedChannelIds, err := h.parseObjectIdFromString(queryParams.ChannelIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.parsePage(queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := domain.ParseChannelFields(queryParams.Fields, queryParams.ShowMembers)
+	if err != nil {
+		return nil, err
+	}
+	projection := fields.Projection(page.Sort)
+
 	var channels domain.ChannelResponseGeneral
+	var positions []*domain.Channel
+	var hasMore bool
+	response := &domain.ChannelResponse{Limit: page.Limit}
+	now := time.Now()
 
 	if queryParams.ShowMembers {
-		channels, err = h.channelRepository.Aggregate(ctx, parsedUserIds, parsedHeaderUserId)
+		channelsWithMembers, more, err := h.channelRepository.Aggregate(ctx, parsedChannelIds, parsedUserIds, parsedHeaderUserId, queryParams.Search, page, queryParams.MembersLimit, projection)
 		if err != nil {
 			return nil, err
 		}
+		positions = make([]*domain.Channel, 0, len(channelsWithMembers))
+		for _, channel := range channelsWithMembers {
+			channel.ApplyDenunciationPolicy(h.denunciationPolicy)
+			channel.RefreshLock(now)
+			positions = append(positions, &channel.Channel)
+		}
+		err = h.attachSanctions(ctx, channelsWithMembers, parsedHeaderUserId, now)
+		if err != nil {
+			return nil, err
+		}
+		channels = channelsWithMembers
+		hasMore = more
 	} else {
-		parsedChannelIds, err := h.parseObjectIdFromString(queryParams.ChannelIDs)
+		listedChannels, more, err := h.channelRepository.List(ctx, parsedChannelIds, parsedUserIds, parsedHeaderUserId, queryParams.Search, page, projection)
+		if err != nil {
+			return nil, err
+		}
+		positions = make([]*domain.Channel, 0, len(listedChannels))
+		for _, channel := range listedChannels {
+			channel.RefreshLock(now)
+			positions = append(positions, channel)
+		}
+		channels = listedChannels
+		hasMore = more
+	}
+
+	err = h.setPageLinks(response, page, positions, hasMore, queryParams.Search != "")
+	if err != nil {
+		return nil, err
+	}
+
+	if queryParams.IncludeTotal {
+		total, err := h.channelRepository.Count(ctx, parsedChannelIds, parsedUserIds, parsedHeaderUserId, queryParams.Search)
 		if err != nil {
 			return nil, err
 		}
+		response.Total = &total
+	}
+
+	response.Channels, err = fields.Select(channels)
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// parsePage reads the sort and the cursor of the listing, a cursor issued for another sort is rejected.
+// Searches are ordered by relevance, which neither a sort nor cursors can express, so they page by number only.
+func (h *ChannelService) parsePage(queryParams helpers.QueryParams) (domain.Page, error) {
+	page := domain.Page{
+		Limit:  queryParams.Limit,
+		Offset: queryParams.Offset,
+		Sort:   domain.DEFAULT_CHANNEL_SORT,
+	}
+	if queryParams.Sort != "" {
+		if queryParams.Search != "" {
+			return page, exceptions.New(exceptions.ErrInvalidSortField, nil)
+		}
+		sort, err := domain.ParseChannelSort(queryParams.Sort)
+		if err != nil {
+			return page, err
+		}
+		page.Sort = sort
+	}
+	if queryParams.Cursor == "" {
+		return page, nil
+	}
+	if queryParams.Search != "" {
+		return page, exceptions.New(exceptions.ErrInvalidCursor, nil)
+	}
+
+	var position domain.ChannelCursor
+	if err := h.cursorSigner.Decode(queryParams.Cursor, &position); err != nil {
+		return page, exceptions.New(exceptions.ErrInvalidCursor, err)
+	}
+	if position.Sort != domain.SortSpec(page.Sort) || len(position.Values) != len(page.Sort) {
+		return page, exceptions.New(exceptions.ErrInvalidCursor, nil)
+	}
+	page.Cursor = &position
+	return page, nil
+}
+
+// setPageLinks fills the cursors around the channels of the page, in display order. The next
+// cursor is set when channels follow and the previous one when the page is not the first.
+// hasMore is reported by the repository in the direction the page was read, which is backward
+// for a previous cursor, so has_more and has_prev are derived from the links instead.
+func (h *ChannelService) setPageLinks(response *domain.ChannelResponse, page domain.Page, channels []*domain.Channel, hasMore bool, searching bool) error {
+	if page.Cursor == nil && hasMore {
+		response.NextPage = page.Offset + 1
+	}
+
+	if !searching && len(channels) > 0 {
+		backward := page.IsBackward()
+		if hasMore || backward {
+			next, err := h.cursorSigner.Encode(domain.CursorAt(channels[len(channels)-1], page.Sort, false))
+			if err != nil {
+				return err
+			}
+			response.NextCursor = next
+		}
+		if (backward && hasMore) || (!backward && (page.Cursor != nil || page.Offset > 0)) {
+			prev, err := h.cursorSigner.Encode(domain.CursorAt(channels[0], page.Sort, true))
+			if err != nil {
+				return err
+			}
+			response.PrevCursor = prev
+		}
+	}
+
+	response.HasMore = response.NextCursor != "" || response.NextPage > 0
+	response.HasPrev = response.PrevCursor != "" || (page.Cursor == nil && page.Offset > 0)
+	return nil
+}
+
+func (h *ChannelService) Update(ctx context.Context, id string, actorId string, request domain.ChannelPatchRequest) error {
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidID, err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return err
+	}
+
+	userIds, err := request.UserIds()
+	if err != nil {
+		return err
+	}
+	err = h.checkUsersExist(ctx, userIds)
+	if err != nil {
+		return err
+	}
 
-		channels, err = h.channelRepository.List(ctx, parsedChannelIds, parsedUserIds, parsedHeaderUserId, queryParams.Limit, queryParams.Offset)
+	flagged, err := h.applyContentPolicy(request.Name, request.Description)
+	if err != nil {
+		return err
+	}
+
+	before, err := h.channelRepository.Get(ctx, parsedId)
+	if err != nil {
+		return err
+	}
+	if request.ChangesMembership() && before.IsLocked(time.Now()) && !isAdmin(before, actorId) {
+		return exceptions.New(exceptions.ErrChannelLocked, nil)
+	}
+
+	fieldsToUpdate := request.ToBsonM()
+	if flagged {
+		fieldsToUpdate["$set"].(bson.M)["flagged"] = true
+		fieldsToUpdate["$addToSet"] = bson.M{"flag_reasons": domain.FLAG_REASON_CONTENT_POLICY}
+	}
+
+	_, err = h.updateAndRecord(ctx, actorId, before, fieldsToUpdate)
+	return err
+}
+
+func (h *ChannelService) Delete(ctx context.Context, id string, actorId string) error {
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidID, err)
+	}
+
+	before, err := h.channelRepository.Get(ctx, parsedId)
+	if err != nil {
+		return err
+	}
+
+	err = h.inTransaction(ctx, func(ctx context.Context) ([]events.Event, error) {
+		err := h.channelRepository.Delete(ctx, parsedId)
 		if err != nil {
 			return nil, err
 		}
+		err = h.auditService.Record(ctx, actorId, before, nil)
+		if err != nil {
+			return nil, err
+		}
+		return events.FromChannelChange(actorId, before, nil), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (h *ChannelService) Discover(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error) {
+	parsedHeaderUserId, err := primitive.ObjectIDFromHex(queryParams.HeaderUserId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	user, err := h.profileRepository.Get(ctx, parsedHeaderUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, hasMore, err := h.channelRepository.Discover(ctx, parsedHeaderUserId, user.Categories, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		return nil, err
 	}
 
 	response := &domain.ChannelResponse{
 		Channels: channels,
+		Limit:    queryParams.Limit,
+		HasMore:  hasMore,
+		HasPrev:  queryParams.Offset > 0,
+	}
+	if hasMore {
+		response.NextPage = queryParams.Offset + 1
+	}
+
+	return response, nil
+}
+
+func (h *ChannelService) Nearby(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error) {
+	parsedHeaderUserId, err := primitive.ObjectIDFromHex(queryParams.HeaderUserId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
 	}
 
-	if channelsParsed, ok := channels.([]interface{}); ok && len(channelsParsed) == int(queryParams.Limit) {
+	point, err := h.resolveLocation(ctx, parsedHeaderUserId, queryParams)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, hasMore, err := h.channelRepository.Nearby(ctx, parsedHeaderUserId, point, queryParams.Radius, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.ChannelResponse{
+		Channels: channels,
+		Limit:    queryParams.Limit,
+		HasMore:  hasMore,
+		HasPrev:  queryParams.Offset > 0,
+	}
+	if hasMore {
 		response.NextPage = queryParams.Offset + 1
 	}
 
 	return response, nil
 }
 
-func (h *ChannelService) Update(ctx context.Context, id string, request domain.ChannelPatchRequest) error {
+// resolveLocation uses the coordinates sent in the query, falling back to the caller's stored location.
+func (h *ChannelService) resolveLocation(ctx context.Context, userId primitive.ObjectID, queryParams helpers.QueryParams) (*domain.GeoPoint, error) {
+	if queryParams.Latitude != "" || queryParams.Longitude != "" {
+		latitude, err := strconv.ParseFloat(queryParams.Latitude, 64)
+		if err != nil {
+			return nil, exceptions.New(exceptions.ErrInvalidLocationField, err)
+		}
+		longitude, err := strconv.ParseFloat(queryParams.Longitude, 64)
+		if err != nil {
+			return nil, exceptions.New(exceptions.ErrInvalidLocationField, err)
+		}
+		if err := domain.ValidateCoordinates(longitude, latitude); err != nil {
+			return nil, err
+		}
+		return domain.NewGeoPoint(longitude, latitude), nil
+	}
+
+	user, err := h.profileRepository.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(user.Location) != 2 {
+		return nil, exceptions.New(exceptions.ErrLocationIsReq, nil)
+	}
+	return domain.ParseLocation(user.Location)
+}
+
+func (h *ChannelService) ListDenunciatedMembers(ctx context.Context, id string, headerUserId string) (*domain.MembersResponse, error) {
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+	parsedHeaderUserId, err := primitive.ObjectIDFromHex(headerUserId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	channel, err := h.channelRepository.Get(ctx, parsedId)
+	if err != nil {
+		return nil, err
+	}
+	if !channel.IsAdmin(parsedHeaderUserId) {
+		return nil, exceptions.New(exceptions.ErrUserIsNotAdmin, nil)
+	}
+
+	members, err := h.profileRepository.ListDenunciated(ctx, channel.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.MembersResponse{
+		Members: members,
+	}, nil
+}
+
+func (h *ChannelService) Lock(ctx context.Context, id string, actorId string, request domain.LockRequest) error {
+	now := time.Now()
+	err := request.Validate(now)
+	if err != nil {
+		return err
+	}
+
+	before, err := h.getAsAdmin(ctx, id, actorId)
+	if err != nil {
+		return err
+	}
+
+	lockedBy, _ := primitive.ObjectIDFromHex(actorId)
+	lock := request.ToChannelLock(lockedBy, now)
+	_, err = h.updateAndRecord(ctx, actorId, before, bson.M{"$set": bson.M{"locked": true, "lock": lock}})
+	return err
+}
+
+func (h *ChannelService) Unlock(ctx context.Context, id string, actorId string) error {
+	before, err := h.getAsAdmin(ctx, id, actorId)
+	if err != nil {
+		return err
+	}
+
+	_, err = h.updateAndRecord(ctx, actorId, before, bson.M{"$set": bson.M{"locked": false, "lock": nil}})
+	return err
+}
+
+func (h *ChannelService) Timeout(ctx context.Context, id string, memberId string, actorId string, request domain.TimeoutRequest) (*domain.Sanction, error) {
+	err := request.Validate(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	channel, parsedMemberId, err := h.getSanctionTarget(ctx, id, memberId, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	issuedBy, _ := primitive.ObjectIDFromHex(actorId)
+	sanction := request.ToSanction(channel.ID, parsedMemberId, issuedBy)
+	err = h.inTransaction(ctx, func(ctx context.Context) ([]events.Event, error) {
+		_, err := h.sanctionRepository.Create(ctx, sanction)
+		if err != nil {
+			return nil, err
+		}
+		err = h.auditService.RecordSanction(ctx, sanction)
+		if err != nil {
+			return nil, err
+		}
+		return []events.Event{events.FromSanction(sanction)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sanction, nil
+}
+
+// Kick removes the member from the channel, the member can still be added back later.
+func (h *ChannelService) Kick(ctx context.Context, id string, memberId string, actorId string, request domain.KickRequest) (*domain.Sanction, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	channel, parsedMemberId, err := h.getSanctionTarget(ctx, id, memberId, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	members, admins := channel.WithoutMember(parsedMemberId)
+	if len(members) < domain.MEMBERS_MINIMUM || len(admins) < domain.ADMINS_MINIMUM {
+		return nil, exceptions.New(exceptions.ErrCannotSanctionMember, nil)
+	}
+
+	issuedBy, _ := primitive.ObjectIDFromHex(actorId)
+	sanction := request.ToSanction(channel.ID, parsedMemberId, issuedBy)
+	var after *domain.Channel
+	err = h.inTransaction(ctx, func(ctx context.Context) ([]events.Event, error) {
+		updated, err := h.updateChannel(ctx, channel.ID, bson.M{"$set": bson.M{"members": members, "admins": admins}})
+		if err != nil {
+			return nil, err
+		}
+		after = updated
+
+		_, err = h.sanctionRepository.Create(ctx, sanction)
+		if err != nil {
+			return nil, err
+		}
+		err = h.auditService.Record(ctx, actorId, channel, after)
+		if err != nil {
+			return nil, err
+		}
+		err = h.auditService.RecordSanction(ctx, sanction)
+		if err != nil {
+			return nil, err
+		}
+		return append(events.FromChannelChange(actorId, channel, after), events.FromSanction(sanction)), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sanction, nil
+}
+
+// RemoveUser takes the user out of every channel, it is driven by the users service so the
+// changes have no actor. It cannot be refused like a kick, so the same rules are enforced the
+// other way: channels left below MEMBERS_MINIMUM are deleted and remaining members are promoted
+// while the channel is below ADMINS_MINIMUM. Removing a user that is not in any channel anymore
+// is a no-op, which keeps redelivered events harmless.
+func (h *ChannelService) RemoveUser(ctx context.Context, userId string) error {
+	parsedUserId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidMemberId, err)
+	}
+
+	channels, err := h.channelRepository.ListByMember(ctx, parsedUserId)
+	if err != nil {
+		return err
+	}
+
+	for _, channel := range channels {
+		err = h.removeFromChannel(ctx, "", channel, parsedUserId)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveMember takes the member out of the channel on behalf of moderation, with the same rules
+// as RemoveUser. Removing a member that already left is a no-op.
+func (h *ChannelService) RemoveMember(ctx context.Context, id string, memberId string, actorId string) error {
 	parsedId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return exceptions.New(exceptions.ErrInvalidID, err)
 	}
+	parsedMemberId, err := primitive.ObjectIDFromHex(memberId)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidMemberId, err)
+	}
 
-	err = request.Validate()
+	channel, err := h.channelRepository.Get(ctx, parsedId)
 	if err != nil {
 		return err
 	}
+	if !channel.IsMember(parsedMemberId) {
+		return nil
+	}
+	return h.removeFromChannel(ctx, actorId, channel, parsedMemberId)
+}
 
-	fieldsToUpdate := request.ToBsonM()
+// removeFromChannel takes the user out of the channel, deleting it when it would fall below MEMBERS_MINIMUM.
+func (h *ChannelService) removeFromChannel(ctx context.Context, actorId string, channel *domain.Channel, userId primitive.ObjectID) error {
+	members, admins := channel.WithoutUser(userId)
+	if len(members) >= domain.MEMBERS_MINIMUM {
+		_, err := h.updateAndRecord(ctx, actorId, channel, bson.M{"$set": bson.M{"members": members, "admins": admins}})
+		return err
+	}
 
-	return h.channelRepository.Update(ctx, parsedId, fieldsToUpdate)
+	return h.inTransaction(ctx, func(ctx context.Context) ([]events.Event, error) {
+		err := h.channelRepository.Delete(ctx, channel.ID)
+		if err != nil {
+			return nil, err
+		}
+		err = h.auditService.Record(ctx, actorId, channel, nil)
+		if err != nil {
+			return nil, err
+		}
+		return events.FromChannelChange(actorId, channel, nil), nil
+	})
 }
 
-func (h *ChannelService) Delete(ctx context.Context, id string) error {
+// SetReportFlag flags or clears the flag the reports put on the channel, an empty actor stands for
+// the automatic flagging once enough reports pile up. A flag put by the content policy is kept.
+func (h *ChannelService) SetReportFlag(ctx context.Context, id string, actorId string, flagged bool) error {
 	parsedId, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return exceptions.New(exceptions.ErrInvalidID, err)
 	}
 
-	return h.channelRepository.Delete(ctx, parsedId)
+	before, err := h.channelRepository.Get(ctx, parsedId)
+	if err != nil {
+		return err
+	}
+	reasons, stillFlagged := before.WithFlagReason(domain.FLAG_REASON_REPORTS, flagged)
+	if stillFlagged == before.Flagged && slices.Equal(reasons, before.FlagReasons) {
+		return nil
+	}
+
+	_, err = h.updateAndRecord(ctx, actorId, before, bson.M{"$set": bson.M{"flagged": stillFlagged, "flag_reasons": reasons}})
+	return err
+}
+
+// getSanctionTarget loads the channel as an admin and checks the member can be sanctioned in it.
+func (h *ChannelService) getSanctionTarget(ctx context.Context, id string, memberId string, actorId string) (*domain.Channel, primitive.ObjectID, error) {
+	parsedMemberId, err := primitive.ObjectIDFromHex(memberId)
+	if err != nil {
+		return nil, primitive.NilObjectID, exceptions.New(exceptions.ErrInvalidMemberId, err)
+	}
+
+	channel, err := h.getAsAdmin(ctx, id, actorId)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+	if !channel.IsMember(parsedMemberId) {
+		return nil, primitive.NilObjectID, exceptions.New(exceptions.ErrUserIsNotMember, nil)
+	}
+	if parsedMemberId.Hex() == actorId {
+		return nil, primitive.NilObjectID, exceptions.New(exceptions.ErrCannotSanctionMember, nil)
+	}
+	return channel, parsedMemberId, nil
+}
+
+// attachSanctions fills the active sanctions of the channels the user administrates.
+func (h *ChannelService) attachSanctions(ctx context.Context, channels []*domain.ChannelWithMembers, userId primitive.ObjectID, now time.Time) error {
+	administrated := make(map[primitive.ObjectID]*domain.ChannelWithMembers)
+	channelIds := make([]primitive.ObjectID, 0)
+	for _, channel := range channels {
+		if channel.HasAdmin(userId) {
+			administrated[channel.ID] = channel
+			channelIds = append(channelIds, channel.ID)
+			channel.Sanctions = make([]*domain.Sanction, 0)
+		}
+	}
+	if len(channelIds) == 0 {
+		return nil
+	}
+
+	sanctions, err := h.sanctionRepository.ListActive(ctx, channelIds, now)
+	if err != nil {
+		return err
+	}
+	for _, sanction := range sanctions {
+		channel := administrated[sanction.ChannelId]
+		channel.Sanctions = append(channel.Sanctions, sanction)
+	}
+	return nil
+}
+
+func (h *ChannelService) getAsAdmin(ctx context.Context, id string, actorId string) (*domain.Channel, error) {
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+
+	channel, err := h.channelRepository.Get(ctx, parsedId)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin(channel, actorId) {
+		return nil, exceptions.New(exceptions.ErrUserIsNotAdmin, nil)
+	}
+	return channel, nil
+}
+
+// updateAndRecord applies the update along with its audit and its events atomically.
+func (h *ChannelService) updateAndRecord(ctx context.Context, actorId string, before *domain.Channel, fields bson.M) (*domain.Channel, error) {
+	var after *domain.Channel
+	err := h.inTransaction(ctx, func(ctx context.Context) ([]events.Event, error) {
+		updated, err := h.updateChannel(ctx, before.ID, fields)
+		if err != nil {
+			return nil, err
+		}
+		after = updated
+		err = h.auditService.Record(ctx, actorId, before, after)
+		if err != nil {
+			return nil, err
+		}
+		return events.FromChannelChange(actorId, before, after), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+func (h *ChannelService) updateChannel(ctx context.Context, id primitive.ObjectID, fields bson.M) (*domain.Channel, error) {
+	err := h.channelRepository.Update(ctx, id, fields)
+	if err != nil {
+		return nil, err
+	}
+	return h.channelRepository.Get(ctx, id)
+}
+
+// inTransaction runs fn and stores the events it returns in the outbox within the same transaction,
+// the outbox relay delivers them once committed.
+func (h *ChannelService) inTransaction(ctx context.Context, fn func(ctx context.Context) ([]events.Event, error)) error {
+	return h.outboxRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		pending, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		return h.outboxRepository.Add(ctx, pending...)
+	})
+}
+
+// checkUsersExist rejects the ids of users unknown to the directory, listing them.
+func (h *ChannelService) checkUsersExist(ctx context.Context, ids []primitive.ObjectID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	unique := make([]primitive.ObjectID, 0, len(ids))
+	seen := make(map[primitive.ObjectID]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	unknown, err := h.userDirectory.Unknown(ctx, unique)
+	if err != nil {
+		return exceptions.New(exceptions.ErrUserDirectoryUnavailable, err)
+	}
+	if len(unknown) > 0 {
+		unknownIds := make([]string, 0, len(unknown))
+		for _, id := range unknown {
+			unknownIds = append(unknownIds, id.Hex())
+		}
+		return exceptions.NewWithUnknownIds(exceptions.ErrUnknownUserIds, unknownIds)
+	}
+	return nil
+}
+
+func isAdmin(channel *domain.Channel, userId string) bool {
+	parsedUserId, err := primitive.ObjectIDFromHex(userId)
+	if err != nil {
+		return false
+	}
+	return channel.IsAdmin(parsedUserId)
+}
+
+// applyContentPolicy checks the name and description in place, masking them when required.
+// It reports whether the channel must be flagged for moderation.
+func (h *ChannelService) applyContentPolicy(name *string, description *string) (bool, error) {
+	var flagged bool
+	if name != nil {
+		result := h.contentPolicy.Check(*name)
+		if result.Rejected {
+			return false, exceptions.New(exceptions.ErrInvalidNameContent, fmt.Errorf("matched rules %v", result.Rules))
+		}
+		*name = result.Text
+		flagged = flagged || result.Flagged
+	}
+	if description != nil {
+		result := h.contentPolicy.Check(*description)
+		if result.Rejected {
+			return false, exceptions.New(exceptions.ErrInvalidDescriptionContent, fmt.Errorf("matched rules %v", result.Rules))
+		}
+		*description = result.Text
+		flagged = flagged || result.Flagged
+	}
+	return flagged, nil
 }
 
 func (*ChannelService) parseObjectIdFromString(ids []string) ([]primitive.ObjectID, error) {
@@ -0,0 +1,362 @@
+package channels
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/audit"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/cursor"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeChannelRepository lists its channels like the MongoDB repository: sorted on the page
+// sort, after or before the cursor, one more channel read to tell whether more follow.
+type fakeChannelRepository struct {
+	channels.Repository
+	channels []*domain.Channel
+}
+
+func (r *fakeChannelRepository) List(ctx context.Context, channelIds []primitive.ObjectID, userIds []primitive.ObjectID, headerUserId primitive.ObjectID, search string, page domain.Page, projection bson.M) ([]*domain.Channel, bool, error) {
+	backward := page.IsBackward()
+	ordered := slices.Clone(r.channels)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return compareOn(page.Sort, sortValues(ordered[i], page.Sort), sortValues(ordered[j], page.Sort)) < 0
+	})
+	if backward {
+		slices.Reverse(ordered)
+	}
+
+	listed := make([]*domain.Channel, 0)
+	for _, channel := range ordered {
+		if page.Cursor != nil {
+			comparison := compareOn(page.Sort, sortValues(channel, page.Sort), page.Cursor.Values)
+			if (!backward && comparison <= 0) || (backward && comparison >= 0) {
+				continue
+			}
+		}
+		listed = append(listed, channel)
+	}
+	if page.Cursor == nil {
+		listed = listed[min(int64(len(listed)), page.Offset*page.Limit):]
+	}
+
+	hasMore := int64(len(listed)) > page.Limit
+	if hasMore {
+		listed = listed[:page.Limit]
+	}
+	if backward {
+		slices.Reverse(listed)
+	}
+	return listed, hasMore, nil
+}
+
+func sortValues(channel *domain.Channel, keys []domain.SortKey) []interface{} {
+	values := make([]interface{}, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, channel.SortValue(key.Field))
+	}
+	return values
+}
+
+// compareOn compares positions in the order of the sort, as the values come back from a cursor.
+func compareOn(keys []domain.SortKey, a []interface{}, b []interface{}) int {
+	for i, key := range keys {
+		comparison := compareValues(a[i], b[i])
+		if key.Descending {
+			comparison = -comparison
+		}
+		if comparison != 0 {
+			return comparison
+		}
+	}
+	return 0
+}
+
+func compareValues(a interface{}, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return int(toInt64(a) - toInt64(b))
+	case int32:
+		return int(toInt64(a) - toInt64(b))
+	case primitive.ObjectID:
+		other := b.(primitive.ObjectID)
+		return bytes.Compare(a[:], other[:])
+	case string:
+		return strings.Compare(a, b.(string))
+	}
+	panic("unexpected sort value")
+}
+
+func toInt64(value interface{}) int64 {
+	switch value := value.(type) {
+	case int:
+		return int64(value)
+	case int32:
+		return int64(value)
+	case int64:
+		return value
+	}
+	panic("unexpected integer")
+}
+
+func newPagingService(t *testing.T, counts ...int) (Service, []primitive.ObjectID) {
+	t.Helper()
+	repository := &fakeChannelRepository{}
+	for _, count := range counts {
+		channel := &domain.Channel{MemberCount: count}
+		channel.ID = primitive.NewObjectID()
+		repository.channels = append(repository.channels, channel)
+	}
+	service := New(repository, "", nil, nil, nil, nil, nil, nil, cursor.NewSigner([]byte("secret")))
+
+	expected := make([]primitive.ObjectID, 0, len(counts))
+	ordered := slices.Clone(repository.channels)
+	keys := []domain.SortKey{{Field: "member_count", Descending: true}, {Field: "_id", Descending: true}}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return compareOn(keys, sortValues(ordered[i], keys), sortValues(ordered[j], keys)) < 0
+	})
+	for _, channel := range ordered {
+		expected = append(expected, channel.ID)
+	}
+	return service, expected
+}
+
+func listPage(t *testing.T, service Service, cursorToken string) *domain.ChannelResponse {
+	t.Helper()
+	return listSortedPage(t, service, "member_count:desc", cursorToken)
+}
+
+func listSortedPage(t *testing.T, service Service, sortSpec string, cursorToken string) *domain.ChannelResponse {
+	t.Helper()
+	response, err := service.List(context.Background(), helpers.QueryParams{
+		HeaderUserId: primitive.NewObjectID().Hex(),
+		Limit:        3,
+		Sort:         sortSpec,
+		Cursor:       cursorToken,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response
+}
+
+func pageIds(response *domain.ChannelResponse) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, 0)
+	for _, channel := range response.Channels.([]*domain.Channel) {
+		ids = append(ids, channel.ID)
+	}
+	return ids
+}
+
+func TestListPagesForwardAndBackward(t *testing.T) {
+	service, expected := newPagingService(t, 5, 2, 9, 2, 2, 7, 1)
+
+	var pages []*domain.ChannelResponse
+	seen := make([]primitive.ObjectID, 0)
+	response := listPage(t, service, "")
+	for {
+		pages = append(pages, response)
+		seen = append(seen, pageIds(response)...)
+		if !response.HasMore {
+			break
+		}
+		if response.NextCursor == "" {
+			t.Fatal("has_more without a next cursor")
+		}
+		response = listPage(t, service, response.NextCursor)
+	}
+	if !slices.Equal(seen, expected) {
+		t.Fatalf("forward pages returned %v, want %v", seen, expected)
+	}
+	if len(pages) != 3 {
+		t.Fatalf("got %d pages, want 3", len(pages))
+	}
+	if pages[0].HasPrev || pages[0].PrevCursor != "" {
+		t.Error("first page has a previous page")
+	}
+	if !pages[2].HasPrev || pages[2].NextCursor != "" {
+		t.Error("last page links are wrong")
+	}
+
+	for i := len(pages) - 1; i > 0; i-- {
+		previous := listPage(t, service, pages[i].PrevCursor)
+		if !slices.Equal(pageIds(previous), pageIds(pages[i-1])) {
+			t.Fatalf("previous page of page %d returned %v, want %v", i, pageIds(previous), pageIds(pages[i-1]))
+		}
+		if !previous.HasMore || previous.NextCursor == "" {
+			t.Errorf("previous page %d does not link to the next one", i-1)
+		}
+		if previous.HasPrev != (i-1 > 0) {
+			t.Errorf("previous page %d has_prev is %v", i-1, previous.HasPrev)
+		}
+	}
+}
+
+func TestListPagesOnSeveralSortKeys(t *testing.T) {
+	repository := &fakeChannelRepository{}
+	for i, name := range []string{"b", "a", "c", "a", "b", "d", "a"} {
+		channel := &domain.Channel{Name: name, MemberCount: i % 2}
+		channel.ID = primitive.NewObjectID()
+		repository.channels = append(repository.channels, channel)
+	}
+	service := New(repository, "", nil, nil, nil, nil, nil, nil, cursor.NewSigner([]byte("secret")))
+
+	keys, err := domain.ParseChannelSort("member_count:desc,name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if domain.SortSpec(keys) != "member_count:desc,name:asc,_id:asc" {
+		t.Fatalf("parsed %s", domain.SortSpec(keys))
+	}
+	ordered := slices.Clone(repository.channels)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return compareOn(keys, sortValues(ordered[i], keys), sortValues(ordered[j], keys)) < 0
+	})
+	expected := make([]primitive.ObjectID, 0)
+	for _, channel := range ordered {
+		expected = append(expected, channel.ID)
+	}
+
+	seen := make([]primitive.ObjectID, 0)
+	response := listSortedPage(t, service, "member_count:desc,name", "")
+	for {
+		seen = append(seen, pageIds(response)...)
+		if !response.HasMore {
+			break
+		}
+		response = listSortedPage(t, service, "member_count:desc,name", response.NextCursor)
+	}
+	if !slices.Equal(seen, expected) {
+		t.Fatalf("pages returned %v, want %v", seen, expected)
+	}
+
+	previous := listSortedPage(t, service, "member_count:desc,name", response.PrevCursor)
+	if !slices.Equal(pageIds(previous), expected[3:6]) {
+		t.Errorf("previous page returned %v, want %v", pageIds(previous), expected[3:6])
+	}
+}
+
+func TestParseChannelSortRejectsInvalidSorts(t *testing.T) {
+	for _, raw := range []string{"", "score", "name,name:desc", "name:up", "name,", "_id"} {
+		_, err := domain.ParseChannelSort(raw)
+		var customErr *exceptions.Error
+		if !errors.As(err, &customErr) || customErr.Err != exceptions.ErrInvalidSortField {
+			t.Errorf("sort %q: got %v, want ErrInvalidSortField", raw, err)
+		}
+	}
+}
+
+func TestListRejectsInvalidCursors(t *testing.T) {
+	service, _ := newPagingService(t, 5, 2, 9, 2)
+	next := listPage(t, service, "").NextCursor
+
+	otherSort, err := cursor.NewSigner([]byte("secret")).Encode(domain.ChannelCursor{Sort: "name:asc,_id:asc", Values: []interface{}{"a", primitive.NewObjectID()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, err := cursor.NewSigner([]byte("other")).Encode(domain.ChannelCursor{Sort: "member_count:desc,_id:desc", Values: []interface{}{9, primitive.NewObjectID()}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"tampered":     "x" + next,
+		"other sort":   otherSort,
+		"other secret": otherSecret,
+	} {
+		_, err := service.List(context.Background(), helpers.QueryParams{
+			HeaderUserId: primitive.NewObjectID().Hex(),
+			Limit:        3,
+			Sort:         "member_count:desc",
+			Cursor:       token,
+		})
+		var customErr *exceptions.Error
+		if !errors.As(err, &customErr) || customErr.Err != exceptions.ErrInvalidCursor {
+			t.Errorf("%s cursor: got %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+// fakeFlagRepository stores a single channel and applies the $set of its updates.
+type fakeFlagRepository struct {
+	channels.Repository
+	channel domain.Channel
+}
+
+func (r *fakeFlagRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Channel, error) {
+	channel := r.channel
+	return &channel, nil
+}
+
+func (r *fakeFlagRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	set := fields["$set"].(bson.M)
+	r.channel.Flagged = set["flagged"].(bool)
+	r.channel.FlagReasons = set["flag_reasons"].([]string)
+	return nil
+}
+
+type fakeAuditService struct {
+	audit.Service
+}
+
+func (s *fakeAuditService) Record(ctx context.Context, actorId string, before *domain.Channel, after *domain.Channel) error {
+	return nil
+}
+
+type fakeOutboxRepository struct {
+	outbox.Repository
+}
+
+func (r *fakeOutboxRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (r *fakeOutboxRepository) Add(ctx context.Context, events ...events.Event) error {
+	return nil
+}
+
+func TestSetReportFlagKeepsContentPolicyFlag(t *testing.T) {
+	for name, test := range map[string]struct {
+		channel     domain.Channel
+		wantFlagged bool
+	}{
+		"content policy": {domain.Channel{Flagged: true, FlagReasons: []string{domain.FLAG_REASON_CONTENT_POLICY}}, true},
+		"reports only":   {domain.Channel{}, false},
+		"unknown source": {domain.Channel{Flagged: true}, true},
+	} {
+		test.channel.ID = primitive.NewObjectID()
+		repository := &fakeFlagRepository{channel: test.channel}
+		service := New(repository, "", nil, &fakeAuditService{}, nil, &fakeOutboxRepository{}, nil, nil, nil)
+
+		err := service.SetReportFlag(context.Background(), test.channel.ID.Hex(), "", true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !repository.channel.Flagged {
+			t.Errorf("%s: channel not flagged by the reports", name)
+		}
+
+		err = service.SetReportFlag(context.Background(), test.channel.ID.Hex(), primitive.NewObjectID().Hex(), false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if repository.channel.Flagged != test.wantFlagged {
+			t.Errorf("%s: flagged is %t once the reports are cleared, want %t", name, repository.channel.Flagged, test.wantFlagged)
+		}
+		if slices.Contains(repository.channel.FlagReasons, domain.FLAG_REASON_REPORTS) {
+			t.Errorf("%s: reports reason kept in %v", name, repository.channel.FlagReasons)
+		}
+	}
+}
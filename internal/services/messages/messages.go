@@ -0,0 +1,157 @@
+package messages
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/messages"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/sanctions"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/contentpolicy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const RATE_LIMIT_WINDOW = time.Minute
+
+type Service interface {
+	Post(ctx context.Context, channelId string, senderId string, request domain.MessageRequest) (*domain.Message, error)
+}
+
+type MessageService struct {
+	messageRepository  messages.Repository
+	channelRepository  channels.Repository
+	sanctionRepository sanctions.Repository
+	contentPolicy      contentpolicy.Policy
+	outboxRepository   outbox.Repository
+}
+
+func New(messageRepository messages.Repository, channelRepository channels.Repository, sanctionRepository sanctions.Repository, contentPolicy contentpolicy.Policy, outboxRepository outbox.Repository) Service {
+	return &MessageService{
+		messageRepository,
+		channelRepository,
+		sanctionRepository,
+		contentPolicy,
+		outboxRepository,
+	}
+}
+
+func (h *MessageService) Post(ctx context.Context, channelId string, senderId string, request domain.MessageRequest) (*domain.Message, error) {
+	parsedChannelId, err := primitive.ObjectIDFromHex(channelId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+	parsedSenderId, err := primitive.ObjectIDFromHex(senderId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := h.channelRepository.Get(ctx, parsedChannelId)
+	if err != nil {
+		return nil, err
+	}
+	if !channel.IsMember(parsedSenderId) {
+		return nil, exceptions.New(exceptions.ErrUserIsNotMember, nil)
+	}
+	if channel.IsLocked(time.Now()) && !channel.IsAdmin(parsedSenderId) {
+		return nil, exceptions.New(exceptions.ErrChannelLocked, nil)
+	}
+
+	err = h.checkTimeout(ctx, channel, parsedSenderId)
+	if err != nil {
+		return nil, err
+	}
+
+	message := request.ToMessage(channel.ID, parsedSenderId)
+	result := h.contentPolicy.Check(message.Content)
+	if result.Rejected {
+		return nil, exceptions.New(exceptions.ErrInvalidMessageContent, fmt.Errorf("matched rules %v", result.Rules))
+	}
+	message.Content = result.Text
+	message.Flagged = result.Flagged
+
+	// the posting limits are acquired in the transaction so a message that fails to be stored does not use them up
+	err = h.outboxRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		err := h.acquireSlowMode(ctx, channel, parsedSenderId)
+		if err != nil {
+			return err
+		}
+		err = h.acquireRate(ctx, channel)
+		if err != nil {
+			return err
+		}
+
+		created, err := h.messageRepository.Create(ctx, message)
+		if err != nil {
+			return err
+		}
+		message = created
+		return h.outboxRepository.Add(ctx, events.FromMessage(created))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// checkTimeout rejects the senders timed out in the channel, telling the client how long to wait.
+func (h *MessageService) checkTimeout(ctx context.Context, channel *domain.Channel, senderId primitive.ObjectID) error {
+	now := time.Now()
+
+	timeout, err := h.sanctionRepository.ActiveTimeout(ctx, channel.ID, senderId, now)
+	if err != nil {
+		return err
+	}
+	if timeout != nil {
+		return exceptions.NewWithRetryAfter(exceptions.ErrMemberTimedOut, timeout.Until.Sub(now))
+	}
+	return nil
+}
+
+// acquireSlowMode takes the slot of the sender in a channel in slow mode, telling the client how long
+// to wait while the slot of its previous message is still running.
+func (h *MessageService) acquireSlowMode(ctx context.Context, channel *domain.Channel, senderId primitive.ObjectID) error {
+	if channel.SlowModeSeconds <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	nextPostAt := now.Add(time.Duration(channel.SlowModeSeconds) * time.Second)
+	acquired, nextPostAt, err := h.messageRepository.AcquireSlowMode(ctx, channel.ID, senderId, now, nextPostAt)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return exceptions.NewWithRetryAfter(exceptions.ErrSlowModeActive, nextPostAt.Sub(now))
+	}
+	return nil
+}
+
+// acquireRate counts the message against the rate limit of the channel, in fixed windows of
+// RATE_LIMIT_WINDOW. The client is told to wait for the next window once the current one is full.
+func (h *MessageService) acquireRate(ctx context.Context, channel *domain.Channel) error {
+	if channel.MessagesPerMinute <= 0 {
+		return nil
+	}
+
+	now := time.Now()
+	windowStart := now.Truncate(RATE_LIMIT_WINDOW)
+	windowEnd := windowStart.Add(RATE_LIMIT_WINDOW)
+	acquired, err := h.messageRepository.AcquireRate(ctx, channel.ID, windowStart, windowEnd, channel.MessagesPerMinute)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return exceptions.NewWithRetryAfter(exceptions.ErrChannelRateLimited, windowEnd.Sub(now))
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package messages
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/messages"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/sanctions"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/contentpolicy"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeMessageRepository counts the messages against the rate limit and slow mode like the MongoDB
+// repository, keeping the last window it was asked for.
+type fakeMessageRepository struct {
+	messages.Repository
+	count       int
+	windowStart time.Time
+	windowEnd   time.Time
+	nextPostAt  map[primitive.ObjectID]time.Time
+	created     []*domain.Message
+	createErr   error
+}
+
+func (r *fakeMessageRepository) Create(ctx context.Context, message *domain.Message) (*domain.Message, error) {
+	if r.createErr != nil {
+		return nil, r.createErr
+	}
+	r.created = append(r.created, message)
+	return message, nil
+}
+
+func (r *fakeMessageRepository) AcquireSlowMode(ctx context.Context, channelId primitive.ObjectID, memberId primitive.ObjectID, now time.Time, nextPostAt time.Time) (bool, time.Time, error) {
+	if r.nextPostAt == nil {
+		r.nextPostAt = make(map[primitive.ObjectID]time.Time)
+	}
+	if r.nextPostAt[memberId].After(now) {
+		return false, r.nextPostAt[memberId], nil
+	}
+	r.nextPostAt[memberId] = nextPostAt
+	return true, nextPostAt, nil
+}
+
+func (r *fakeMessageRepository) AcquireRate(ctx context.Context, channelId primitive.ObjectID, windowStart time.Time, windowEnd time.Time, limit int) (bool, error) {
+	r.windowStart, r.windowEnd = windowStart, windowEnd
+	if r.count >= limit {
+		return false, nil
+	}
+	r.count++
+	return true, nil
+}
+
+type fakeChannelRepository struct {
+	channels.Repository
+	channel *domain.Channel
+}
+
+func (r *fakeChannelRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Channel, error) {
+	return r.channel, nil
+}
+
+type fakeSanctionRepository struct {
+	sanctions.Repository
+}
+
+func (r *fakeSanctionRepository) ActiveTimeout(ctx context.Context, channelId primitive.ObjectID, memberId primitive.ObjectID, now time.Time) (*domain.Sanction, error) {
+	return nil, nil
+}
+
+// fakeOutboxRepository rolls the rate limit and slow mode of the message repository back when the transaction fails.
+type fakeOutboxRepository struct {
+	outbox.Repository
+	messageRepository *fakeMessageRepository
+}
+
+func (r *fakeOutboxRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	count := r.messageRepository.count
+	nextPostAt := make(map[primitive.ObjectID]time.Time)
+	for memberId, at := range r.messageRepository.nextPostAt {
+		nextPostAt[memberId] = at
+	}
+	err := fn(ctx)
+	if err != nil {
+		r.messageRepository.count = count
+		r.messageRepository.nextPostAt = nextPostAt
+	}
+	return err
+}
+
+func (r *fakeOutboxRepository) Add(ctx context.Context, events ...events.Event) error {
+	return nil
+}
+
+func TestPostRateLimitsChannel(t *testing.T) {
+	senderId := primitive.NewObjectID()
+	channel := &domain.Channel{Members: []primitive.ObjectID{senderId}, MessagesPerMinute: 2}
+	channel.ID = primitive.NewObjectID()
+	policy, err := contentpolicy.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageRepository := &fakeMessageRepository{}
+	service := New(messageRepository, &fakeChannelRepository{channel: channel}, &fakeSanctionRepository{}, policy, &fakeOutboxRepository{messageRepository: messageRepository})
+
+	post := func() error {
+		_, err := service.Post(context.Background(), channel.ID.Hex(), senderId.Hex(), domain.MessageRequest{Content: "hello"})
+		return err
+	}
+	for i := 0; i < channel.MessagesPerMinute; i++ {
+		if err := post(); err != nil {
+			t.Fatalf("post %d: %v", i, err)
+		}
+	}
+
+	before := time.Now()
+	err = post()
+	var customErr *exceptions.Error
+	if !errors.As(err, &customErr) || customErr.Err != exceptions.ErrChannelRateLimited {
+		t.Fatalf("got %v, want ErrChannelRateLimited", err)
+	}
+	if messageRepository.windowEnd.Sub(messageRepository.windowStart) != RATE_LIMIT_WINDOW || !messageRepository.windowStart.Equal(messageRepository.windowStart.Truncate(RATE_LIMIT_WINDOW)) {
+		t.Errorf("counted in window %v - %v", messageRepository.windowStart, messageRepository.windowEnd)
+	}
+	if customErr.RetryAfter <= 0 || customErr.RetryAfter > messageRepository.windowEnd.Sub(before) {
+		t.Errorf("retry after %v, want until the next window at %v", customErr.RetryAfter, messageRepository.windowEnd)
+	}
+	if len(messageRepository.created) != channel.MessagesPerMinute {
+		t.Errorf("created %d messages, want %d", len(messageRepository.created), channel.MessagesPerMinute)
+	}
+
+	response := exceptions.HandleExceptions(err)
+	if response.Code != http.StatusTooManyRequests || response.RetryAfter < 1 || response.RetryAfter > 60 {
+		t.Errorf("responded %d with retry after %d", response.Code, response.RetryAfter)
+	}
+}
+
+func TestPostEnforcesSlowMode(t *testing.T) {
+	senderId := primitive.NewObjectID()
+	otherId := primitive.NewObjectID()
+	channel := &domain.Channel{Members: []primitive.ObjectID{senderId, otherId}, SlowModeSeconds: 30}
+	channel.ID = primitive.NewObjectID()
+	policy, err := contentpolicy.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageRepository := &fakeMessageRepository{}
+	service := New(messageRepository, &fakeChannelRepository{channel: channel}, &fakeSanctionRepository{}, policy, &fakeOutboxRepository{messageRepository: messageRepository})
+
+	post := func(senderId primitive.ObjectID) error {
+		_, err := service.Post(context.Background(), channel.ID.Hex(), senderId.Hex(), domain.MessageRequest{Content: "hello"})
+		return err
+	}
+	if err := post(senderId); err != nil {
+		t.Fatal(err)
+	}
+
+	err = post(senderId)
+	var customErr *exceptions.Error
+	if !errors.As(err, &customErr) || customErr.Err != exceptions.ErrSlowModeActive {
+		t.Fatalf("got %v, want ErrSlowModeActive", err)
+	}
+	if customErr.RetryAfter <= 0 || customErr.RetryAfter > 30*time.Second {
+		t.Errorf("retry after %v, want within the slow mode", customErr.RetryAfter)
+	}
+
+	if err := post(otherId); err != nil {
+		t.Errorf("slow mode of another member: %v", err)
+	}
+}
+
+func TestPostReleasesLimitsWhenStoringFails(t *testing.T) {
+	senderId := primitive.NewObjectID()
+	channel := &domain.Channel{Members: []primitive.ObjectID{senderId}, SlowModeSeconds: 30, MessagesPerMinute: 1}
+	channel.ID = primitive.NewObjectID()
+	policy, err := contentpolicy.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	messageRepository := &fakeMessageRepository{createErr: exceptions.New(exceptions.ErrDatabaseFailure, errors.New("write failed"))}
+	service := New(messageRepository, &fakeChannelRepository{channel: channel}, &fakeSanctionRepository{}, policy, &fakeOutboxRepository{messageRepository: messageRepository})
+
+	post := func() error {
+		_, err := service.Post(context.Background(), channel.ID.Hex(), senderId.Hex(), domain.MessageRequest{Content: "hello"})
+		return err
+	}
+	if err := post(); err == nil {
+		t.Fatal("post did not fail")
+	}
+
+	messageRepository.createErr = nil
+	if err := post(); err != nil {
+		t.Errorf("post after a failed one: %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/backoff"
+)
+
+const (
+	RETRY_BASE_DELAY = time.Second
+	RETRY_MAX_DELAY  = 5 * time.Minute
+)
+
+type Relay interface {
+	Relay(ctx context.Context) (int, error)
+	Start(ctx context.Context)
+}
+
+type OutboxRelay struct {
+	outboxRepository   outbox.Repository
+	eventPublisher     events.EventPublisher
+	interval           time.Duration
+	batchSize          int64
+	deliveredRetention time.Duration
+	maxAttempts        int
+}
+
+func New(outboxRepository outbox.Repository, eventPublisher events.EventPublisher, interval time.Duration, batchSize int64, deliveredRetention time.Duration, maxAttempts int) Relay {
+	return &OutboxRelay{
+		outboxRepository,
+		eventPublisher,
+		interval,
+		batchSize,
+		deliveredRetention,
+		maxAttempts,
+	}
+}
+
+// Relay delivers a batch of due entries. Once an entry of a channel fails, the following entries
+// of that channel wait for its retry so consumers see them in order, while the other channels go on.
+// An entry failing its last attempt is parked as dead and no longer holds its channel back.
+func (h *OutboxRelay) Relay(ctx context.Context) (int, error) {
+	now := time.Now()
+	entries, err := h.outboxRepository.ListPending(ctx, now, h.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := make(map[string]bool)
+	delivered := 0
+	for _, entry := range entries {
+		if blocked[entry.ChannelId] {
+			continue
+		}
+
+		var event events.Event
+		err := json.Unmarshal([]byte(entry.Payload), &event)
+		if err == nil {
+			err = h.eventPublisher.Publish(ctx, event)
+		}
+		if err != nil {
+			if h.maxAttempts > 0 && entry.Attempts+1 >= h.maxAttempts {
+				log.Printf("outbox entry %s of event %s is dead after %d attempts: %v", entry.ID.Hex(), entry.EventId, entry.Attempts+1, err)
+				if err := h.outboxRepository.MarkDead(ctx, entry.ID, err); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			blocked[entry.ChannelId] = true
+			if err := h.outboxRepository.MarkFailed(ctx, entry.ID, now.Add(backoff.Exponential(entry.Attempts, RETRY_BASE_DELAY, RETRY_MAX_DELAY)), err); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		if err := h.outboxRepository.MarkDelivered(ctx, entry.ID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// Start relays the outbox until ctx is cancelled. Only the instance holding the relay lease relays,
+// the others stand by to take over, since concurrent relays would publish the entries twice and
+// out of order.
+func (h *OutboxRelay) Start(ctx context.Context) {
+	if h.interval <= 0 {
+		return
+	}
+
+	for {
+		_, err := h.outboxRepository.HoldLease(ctx, h.relayEveryInterval)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Println("outbox relay stopped:", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(h.interval):
+		}
+	}
+}
+
+// relayEveryInterval relays the outbox on every interval and removes the entries delivered for
+// longer than the retention, until ctx is cancelled.
+func (h *OutboxRelay) relayEveryInterval(ctx context.Context) error {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := h.Relay(ctx); err != nil {
+				log.Println("outbox relay failed:", err)
+			}
+			if _, err := h.outboxRepository.DeleteDelivered(ctx, time.Now().Add(-h.deliveredRetention)); err != nil {
+				log.Println("outbox cleanup failed:", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeRepository keeps the entries in memory and lists them like the MongoDB repository:
+// due entries in creation order, leaving out the channels that have an entry in backoff.
+type fakeRepository struct {
+	outbox.Repository
+	entries   []*domain.OutboxEntry
+	leaseHeld bool
+}
+
+// HoldLease runs fn when the lease is free for this relay, until ctx is cancelled.
+func (r *fakeRepository) HoldLease(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	if !r.leaseHeld {
+		return false, nil
+	}
+	return true, fn(ctx)
+}
+
+func (r *fakeRepository) DeleteDelivered(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (r *fakeRepository) add(t *testing.T, channelId string, eventId string) *domain.OutboxEntry {
+	t.Helper()
+	payload, err := json.Marshal(events.Event{ID: eventId, Type: events.CHANNEL_UPDATED, ChannelId: channelId})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := &domain.OutboxEntry{EventId: eventId, ChannelId: channelId, Payload: string(payload)}
+	entry.ID = primitive.NewObjectID()
+	r.entries = append(r.entries, entry)
+	return entry
+}
+
+func (r *fakeRepository) ListPending(ctx context.Context, now time.Time, limit int64) ([]*domain.OutboxEntry, error) {
+	waiting := make(map[string]bool)
+	for _, entry := range r.entries {
+		if entry.DeliveredAt == nil && entry.DeadAt == nil && entry.NextAttemptAt.After(now) {
+			waiting[entry.ChannelId] = true
+		}
+	}
+	pending := make([]*domain.OutboxEntry, 0)
+	for _, entry := range r.entries {
+		if entry.DeliveredAt == nil && entry.DeadAt == nil && !waiting[entry.ChannelId] && int64(len(pending)) < limit {
+			pending = append(pending, entry)
+		}
+	}
+	return pending, nil
+}
+
+func (r *fakeRepository) MarkDelivered(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	r.find(id).DeliveredAt = &now
+	return nil
+}
+
+func (r *fakeRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time, cause error) error {
+	entry := r.find(id)
+	entry.Attempts++
+	entry.NextAttemptAt = nextAttemptAt
+	entry.LastError = cause.Error()
+	return nil
+}
+
+func (r *fakeRepository) MarkDead(ctx context.Context, id primitive.ObjectID, cause error) error {
+	now := time.Now()
+	entry := r.find(id)
+	entry.Attempts++
+	entry.DeadAt = &now
+	entry.LastError = cause.Error()
+	return nil
+}
+
+func (r *fakeRepository) find(id primitive.ObjectID) *domain.OutboxEntry {
+	for _, entry := range r.entries {
+		if entry.ID == id {
+			return entry
+		}
+	}
+	return nil
+}
+
+// failingPublisher fails the events in failures until they are removed from it.
+type failingPublisher struct {
+	*events.InMemoryPublisher
+	failures map[string]bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, published ...events.Event) error {
+	for _, event := range published {
+		if p.failures[event.ID] {
+			return errors.New("broker unavailable")
+		}
+	}
+	return p.InMemoryPublisher.Publish(ctx, published...)
+}
+
+func publishedIds(publisher *failingPublisher) []string {
+	ids := make([]string, 0)
+	for _, event := range publisher.Events() {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func assertIds(t *testing.T, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("published %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRelayDeliversPendingEntries(t *testing.T) {
+	repository := &fakeRepository{}
+	repository.add(t, "a", "a1")
+	repository.add(t, "b", "b1")
+	repository.add(t, "a", "a2")
+	publisher := &failingPublisher{events.NewInMemoryPublisher(), map[string]bool{}}
+	relay := New(repository, publisher, time.Second, 10, time.Hour, 3)
+
+	delivered, err := relay.Relay(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 3 {
+		t.Errorf("delivered %d entries, want 3", delivered)
+	}
+	assertIds(t, publishedIds(publisher), "a1", "b1", "a2")
+
+	delivered, err = relay.Relay(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 0 {
+		t.Errorf("delivered %d entries again, want 0", delivered)
+	}
+}
+
+func TestRelayRetriesFailedEntryBeforeTheFollowingOnes(t *testing.T) {
+	repository := &fakeRepository{}
+	failed := repository.add(t, "a", "a1")
+	repository.add(t, "b", "b1")
+	repository.add(t, "a", "a2")
+	publisher := &failingPublisher{events.NewInMemoryPublisher(), map[string]bool{"a1": true}}
+	relay := New(repository, publisher, time.Second, 10, time.Hour, 3)
+
+	before := time.Now()
+	if _, err := relay.Relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertIds(t, publishedIds(publisher), "b1")
+	if failed.Attempts != 1 || failed.LastError == "" {
+		t.Errorf("failed entry has %d attempts and error %q", failed.Attempts, failed.LastError)
+	}
+	if !failed.NextAttemptAt.After(before) {
+		t.Errorf("failed entry is retried at %v, want a backoff", failed.NextAttemptAt)
+	}
+
+	// the channel waits for the retry of its failed entry
+	if _, err := relay.Relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertIds(t, publishedIds(publisher), "b1")
+
+	delete(publisher.failures, "a1")
+	failed.NextAttemptAt = time.Now().Add(-time.Second)
+	if _, err := relay.Relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	assertIds(t, publishedIds(publisher), "b1", "a1", "a2")
+}
+
+func TestRelayParksEntryOutOfAttempts(t *testing.T) {
+	repository := &fakeRepository{}
+	dead := repository.add(t, "a", "a1")
+	dead.Attempts = 2
+	repository.add(t, "a", "a2")
+	publisher := &failingPublisher{events.NewInMemoryPublisher(), map[string]bool{"a1": true}}
+	relay := New(repository, publisher, time.Second, 10, time.Hour, 3)
+
+	if _, err := relay.Relay(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if dead.DeadAt == nil {
+		t.Fatal("entry out of attempts is not dead")
+	}
+	assertIds(t, publishedIds(publisher), "a2")
+}
+
+func TestStartRelaysOnlyWhileHoldingTheLease(t *testing.T) {
+	for _, held := range []bool{false, true} {
+		repository := &fakeRepository{leaseHeld: held}
+		repository.add(t, "a", "a1")
+		publisher := &failingPublisher{events.NewInMemoryPublisher(), map[string]bool{}}
+		relay := New(repository, publisher, 5*time.Millisecond, 10, time.Hour, 3)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		relay.Start(ctx)
+		cancel()
+
+		if held {
+			assertIds(t, publishedIds(publisher), "a1")
+		} else {
+			assertIds(t, publishedIds(publisher))
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package profiles
+
+import (
+	"context"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/users"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service interface {
+	Backfill(ctx context.Context, batchSize int64) (int, error)
+}
+
+type ProfileService struct {
+	userRepository    users.Repository
+	profileRepository profiles.Repository
+}
+
+func New(userRepository users.Repository, profileRepository profiles.Repository) Service {
+	return &ProfileService{
+		userRepository,
+		profileRepository,
+	}
+}
+
+// Backfill projects every user of the users service into the local profiles. Each profile is
+// versioned with the last update of its user, so running it while events are consumed never
+// overwrites a newer profile and running it again is harmless.
+func (h *ProfileService) Backfill(ctx context.Context, batchSize int64) (int, error) {
+	total := 0
+	afterId := primitive.NilObjectID
+	for {
+		users, err := h.userRepository.ListAfter(ctx, afterId, batchSize)
+		if err != nil {
+			return total, err
+		}
+
+		for _, user := range users {
+			if err := h.profileRepository.Upsert(ctx, user.ToProfile(user.UpdatedAt)); err != nil {
+				return total, err
+			}
+			total++
+		}
+
+		if int64(len(users)) < batchSize {
+			return total, nil
+		}
+		afterId = users[len(users)-1].ID
+	}
+}
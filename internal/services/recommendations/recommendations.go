@@ -0,0 +1,115 @@
+package recommendations
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service interface {
+	Recommend(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error)
+}
+
+type RecommendationService struct {
+	channelRepository channels.Repository
+	profileRepository profiles.Repository
+	weights           domain.RecommendationWeights
+	candidatePool     int64
+}
+
+func New(channelRepository channels.Repository, profileRepository profiles.Repository, weights domain.RecommendationWeights, candidatePool int64) Service {
+	return &RecommendationService{
+		channelRepository,
+		profileRepository,
+		weights,
+		candidatePool,
+	}
+}
+
+func (h *RecommendationService) Recommend(ctx context.Context, queryParams helpers.QueryParams) (*domain.ChannelResponse, error) {
+	parsedHeaderUserId, err := primitive.ObjectIDFromHex(queryParams.HeaderUserId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	user, err := h.profileRepository.Get(ctx, parsedHeaderUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	coMembers, err := h.channelRepository.CoMembers(ctx, parsedHeaderUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	var userLocation *domain.GeoPoint
+	if len(user.Location) == 2 {
+		userLocation, _ = domain.ParseLocation(user.Location)
+	}
+
+	now := time.Now()
+	query := domain.RecommendationQuery{
+		UserId:     parsedHeaderUserId,
+		Categories: user.Categories,
+		CoMembers:  coMembers,
+		Location:   userLocation,
+		Weights:    h.weights,
+		Pool:       h.candidatePool,
+		Now:        now,
+		Limit:      queryParams.Limit,
+		Offset:     queryParams.Offset,
+	}
+	channels, hasMore, err := h.channelRepository.Recommend(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	for _, channel := range channels {
+		explain(channel, len(user.Categories), now)
+	}
+
+	response := &domain.ChannelResponse{
+		Channels: channels,
+		Limit:    queryParams.Limit,
+		HasMore:  hasMore,
+		HasPrev:  queryParams.Offset > 0,
+	}
+	if response.HasMore {
+		response.NextPage = queryParams.Offset + 1
+	}
+
+	// the ranking ends with the pool, past it the client is told the remaining candidates were left out
+	if !hasMore && (queryParams.Offset+1)*queryParams.Limit >= h.candidatePool {
+		candidates, err := h.channelRepository.CountRecommendable(ctx, query, h.candidatePool+1)
+		if err != nil {
+			return nil, err
+		}
+		response.PoolLimitReached = candidates > h.candidatePool
+	}
+
+	return response, nil
+}
+
+// explain fills the reasons the channel is recommended from the signals its score was ranked on.
+func explain(channel *domain.RecommendedChannel, userCategories int, now time.Time) {
+	channel.Reasons = make([]string, 0)
+
+	if userCategories > 0 && channel.CategoryOverlap > 0 {
+		channel.Reasons = append(channel.Reasons, fmt.Sprintf("shares %d of your categories", channel.CategoryOverlap))
+	}
+	if channel.Distance != nil {
+		channel.Reasons = append(channel.Reasons, fmt.Sprintf("%.1f km away from you", *channel.Distance/1000))
+	}
+	if channel.CoMemberCount > 0 {
+		channel.Reasons = append(channel.Reasons, fmt.Sprintf("%d people from your channels are members", channel.CoMemberCount))
+	}
+	if channel.LastMessageAt != nil && now.Sub(*channel.LastMessageAt) <= domain.ACTIVITY_HALF_LIFE_DAYS*24*time.Hour {
+		channel.Reasons = append(channel.Reasons, "recently active")
+	}
+}
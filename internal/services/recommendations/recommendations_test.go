@@ -0,0 +1,77 @@
+package recommendations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeChannelRepository ranks its candidates like the MongoDB repository, keeping only the
+// first pool of them.
+type fakeChannelRepository struct {
+	channels.Repository
+	candidates int64
+}
+
+func (r *fakeChannelRepository) CoMembers(ctx context.Context, userId primitive.ObjectID) ([]primitive.ObjectID, error) {
+	return nil, nil
+}
+
+func (r *fakeChannelRepository) Recommend(ctx context.Context, query domain.RecommendationQuery) ([]*domain.RecommendedChannel, bool, error) {
+	ranked := min(r.candidates, query.Pool)
+	channels := make([]*domain.RecommendedChannel, 0)
+	for i := query.Offset * query.Limit; i < ranked && int64(len(channels)) <= query.Limit; i++ {
+		channels = append(channels, &domain.RecommendedChannel{})
+	}
+	hasMore := int64(len(channels)) > query.Limit
+	if hasMore {
+		channels = channels[:query.Limit]
+	}
+	return channels, hasMore, nil
+}
+
+func (r *fakeChannelRepository) CountRecommendable(ctx context.Context, query domain.RecommendationQuery, limit int64) (int64, error) {
+	return min(r.candidates, limit), nil
+}
+
+type fakeProfileRepository struct {
+	profiles.Repository
+}
+
+func (r *fakeProfileRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.UserProfile, error) {
+	return &domain.UserProfile{}, nil
+}
+
+func TestRecommendTellsWhenPoolLimitIsReached(t *testing.T) {
+	for name, test := range map[string]struct {
+		candidates       int64
+		offset           int64
+		wantHasMore      bool
+		wantLimitReached bool
+	}{
+		"first page":               {candidates: 50, offset: 0, wantHasMore: true},
+		"last page of a full pool": {candidates: 50, offset: 1, wantLimitReached: true},
+		"past the pool":            {candidates: 50, offset: 3, wantLimitReached: true},
+		"pool not filled":          {candidates: 15, offset: 1},
+		"pool exactly filled":      {candidates: 20, offset: 1},
+	} {
+		service := New(&fakeChannelRepository{candidates: test.candidates}, &fakeProfileRepository{}, domain.RecommendationWeights{}, 20)
+
+		response, err := service.Recommend(context.Background(), helpers.QueryParams{
+			HeaderUserId: primitive.NewObjectID().Hex(),
+			Limit:        10,
+			Offset:       test.offset,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.HasMore != test.wantHasMore || response.PoolLimitReached != test.wantLimitReached {
+			t.Errorf("%s: has_more %t and pool_limit_reached %t, want %t and %t", name, response.HasMore, response.PoolLimitReached, test.wantHasMore, test.wantLimitReached)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/messages"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/reports"
+	channelsService "github.com/ADAGroupTcc/ms-channels-api/internal/services/channels"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service interface {
+	Create(ctx context.Context, channelId string, reporterId string, request domain.ReportRequest) (*domain.Report, error)
+	List(ctx context.Context, queryParams helpers.QueryParams) (*domain.ReportResponse, error)
+	Assign(ctx context.Context, id string, staffId string, request domain.ReportAssignRequest) error
+	Resolve(ctx context.Context, id string, staffId string, request domain.ReportResolveRequest) error
+}
+
+type ReportService struct {
+	reportRepository  reports.Repository
+	channelRepository channels.Repository
+	messageRepository messages.Repository
+	outboxRepository  outbox.Repository
+	channelService    channelsService.Service
+	staffIds          map[string]bool
+	flagThreshold     int64
+}
+
+func New(reportRepository reports.Repository, channelRepository channels.Repository, messageRepository messages.Repository, outboxRepository outbox.Repository, channelService channelsService.Service, staffIds []string, flagThreshold int64) Service {
+	staff := make(map[string]bool, len(staffIds))
+	for _, staffId := range staffIds {
+		staff[staffId] = true
+	}
+	return &ReportService{
+		reportRepository,
+		channelRepository,
+		messageRepository,
+		outboxRepository,
+		channelService,
+		staff,
+		flagThreshold,
+	}
+}
+
+func (h *ReportService) Create(ctx context.Context, channelId string, reporterId string, request domain.ReportRequest) (*domain.Report, error) {
+	parsedChannelId, err := primitive.ObjectIDFromHex(channelId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+	parsedReporterId, err := primitive.ObjectIDFromHex(reporterId)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	channel, err := h.channelRepository.Get(ctx, parsedChannelId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := request.ToReport(channel.ID, parsedReporterId)
+	switch report.TargetType {
+	case domain.REPORT_TARGET_MEMBER:
+		if !containsId(channel.Members, report.TargetId) {
+			return nil, exceptions.New(exceptions.ErrInvalidReportTarget, nil)
+		}
+	case domain.REPORT_TARGET_MESSAGE:
+		message, err := h.messageRepository.Get(ctx, report.TargetId)
+		if err != nil {
+			return nil, err
+		}
+		if message == nil || message.ChannelId != channel.ID {
+			return nil, exceptions.New(exceptions.ErrInvalidReportTarget, nil)
+		}
+	}
+
+	report, err = h.reportRepository.Create(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.flagIfNeeded(ctx, report)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// flagIfNeeded flags every unresolved report of the target, and the channel itself when it is the target,
+// once the number of unresolved reports reaches the configured threshold.
+func (h *ReportService) flagIfNeeded(ctx context.Context, report *domain.Report) error {
+	if h.flagThreshold <= 0 {
+		return nil
+	}
+
+	count, err := h.reportRepository.CountOpenByTarget(ctx, report.TargetType, report.TargetId)
+	if err != nil {
+		return err
+	}
+	if count < h.flagThreshold {
+		return nil
+	}
+
+	err = h.reportRepository.FlagByTarget(ctx, report.TargetType, report.TargetId)
+	if err != nil {
+		return err
+	}
+	report.Flagged = true
+
+	if report.TargetType == domain.REPORT_TARGET_CHANNEL {
+		return h.channelService.SetReportFlag(ctx, report.ChannelId.Hex(), "", true)
+	}
+	return nil
+}
+
+func (h *ReportService) List(ctx context.Context, queryParams helpers.QueryParams) (*domain.ReportResponse, error) {
+	if !h.staffIds[queryParams.HeaderUserId] {
+		return nil, exceptions.New(exceptions.ErrUserIsNotStaff, nil)
+	}
+	if queryParams.Status != "" && !domain.IsValidReportStatus(queryParams.Status) {
+		return nil, exceptions.New(exceptions.ErrInvalidReportStatus, nil)
+	}
+
+	reports, err := h.reportRepository.List(ctx, queryParams.Status, queryParams.TargetType, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.ReportResponse{
+		Reports: reports,
+	}
+	if len(reports) == int(queryParams.Limit) {
+		response.NextPage = queryParams.Offset + 1
+	}
+
+	return response, nil
+}
+
+func (h *ReportService) Assign(ctx context.Context, id string, staffId string, request domain.ReportAssignRequest) error {
+	report, err := h.getForStaff(ctx, id, staffId)
+	if err != nil {
+		return err
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return err
+	}
+	assigneeId, _ := primitive.ObjectIDFromHex(request.AssigneeId)
+
+	return h.reportRepository.Update(ctx, report.ID, bson.M{
+		"assignee_id": assigneeId,
+		"status":      domain.REPORT_STATUS_ASSIGNED,
+	})
+}
+
+func (h *ReportService) Resolve(ctx context.Context, id string, staffId string, request domain.ReportResolveRequest) error {
+	report, err := h.getForStaff(ctx, id, staffId)
+	if err != nil {
+		return err
+	}
+
+	err = request.Validate()
+	if err != nil {
+		return err
+	}
+	resolvedBy, _ := primitive.ObjectIDFromHex(staffId)
+	resolution := bson.M{
+		"status":          domain.REPORT_STATUS_RESOLVED,
+		"action":          request.Action,
+		"resolution_note": request.Note,
+		"resolved_by":     resolvedBy,
+		"resolved_at":     time.Now(),
+	}
+
+	if request.Action == domain.REPORT_ACTION_CONTENT_REMOVE {
+		err = h.removeContent(ctx, report, resolution)
+	} else {
+		err = h.applyAction(ctx, report, staffId, request.Action)
+		if err == nil {
+			err = h.reportRepository.Update(ctx, report.ID, resolution)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.TargetType != domain.REPORT_TARGET_CHANNEL || request.Action == domain.REPORT_ACTION_CHANNEL_DELETE {
+		return nil
+	}
+	count, err := h.reportRepository.CountOpenByTarget(ctx, report.TargetType, report.TargetId)
+	if err != nil {
+		return err
+	}
+	if count < h.flagThreshold {
+		return h.channelService.SetReportFlag(ctx, report.ChannelId.Hex(), staffId, false)
+	}
+	return nil
+}
+
+// removeContent deletes the reported message in the transaction resolving the report, only
+// messages can have their content removed.
+func (h *ReportService) removeContent(ctx context.Context, report *domain.Report, resolution bson.M) error {
+	if report.TargetType != domain.REPORT_TARGET_MESSAGE {
+		return exceptions.New(exceptions.ErrInvalidReportAction, nil)
+	}
+	return h.outboxRepository.WithTransaction(ctx, func(ctx context.Context) error {
+		err := h.messageRepository.Delete(ctx, report.TargetId)
+		if err != nil {
+			return err
+		}
+		return h.reportRepository.Update(ctx, report.ID, resolution)
+	})
+}
+
+// applyAction carries out the resolution of the report: the channel is deleted, or the reported
+// member, or the sender of the reported message, is removed from it. Warnings are given outside
+// the service and only recorded.
+func (h *ReportService) applyAction(ctx context.Context, report *domain.Report, staffId string, action string) error {
+	switch action {
+	case domain.REPORT_ACTION_CHANNEL_DELETE:
+		err := h.channelService.Delete(ctx, report.ChannelId.Hex(), staffId)
+		var customErr *exceptions.Error
+		if errors.As(err, &customErr) && customErr.Err == exceptions.ErrChannelNotFound {
+			return nil
+		}
+		return err
+	case domain.REPORT_ACTION_MEMBER_REMOVED:
+		memberId := report.TargetId
+		switch report.TargetType {
+		case domain.REPORT_TARGET_CHANNEL:
+			return exceptions.New(exceptions.ErrInvalidReportAction, nil)
+		case domain.REPORT_TARGET_MESSAGE:
+			message, err := h.messageRepository.Get(ctx, report.TargetId)
+			if err != nil {
+				return err
+			}
+			if message == nil {
+				return exceptions.New(exceptions.ErrInvalidReportTarget, nil)
+			}
+			memberId = message.SenderId
+		}
+		return h.channelService.RemoveMember(ctx, report.ChannelId.Hex(), memberId.Hex(), staffId)
+	}
+	return nil
+}
+
+func (h *ReportService) getForStaff(ctx context.Context, id string, staffId string) (*domain.Report, error) {
+	if !h.staffIds[staffId] {
+		return nil, exceptions.New(exceptions.ErrUserIsNotStaff, nil)
+	}
+
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+
+	report, err := h.reportRepository.Get(ctx, parsedId)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status == domain.REPORT_STATUS_RESOLVED {
+		return nil, exceptions.New(exceptions.ErrReportAlreadyResolved, nil)
+	}
+	return report, nil
+}
+
+func containsId(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, current := range ids {
+		if current == id {
+			return true
+		}
+	}
+	return false
+}
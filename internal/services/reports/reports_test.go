@@ -0,0 +1,123 @@
+package reports
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/messages"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/outbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/reports"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeReportRepository struct {
+	reports.Repository
+	report *domain.Report
+}
+
+func (r *fakeReportRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Report, error) {
+	return r.report, nil
+}
+
+func (r *fakeReportRepository) Update(ctx context.Context, id primitive.ObjectID, fields bson.M) error {
+	r.report.Status = fields["status"].(string)
+	r.report.Action = fields["action"].(string)
+	return nil
+}
+
+type fakeMessageRepository struct {
+	messages.Repository
+	messages map[primitive.ObjectID]*domain.Message
+}
+
+func (r *fakeMessageRepository) Delete(ctx context.Context, id primitive.ObjectID) error {
+	delete(r.messages, id)
+	return nil
+}
+
+// fakeOutboxRepository rolls the report and the messages back when the transaction fails.
+type fakeOutboxRepository struct {
+	outbox.Repository
+	reportRepository  *fakeReportRepository
+	messageRepository *fakeMessageRepository
+	fail              error
+}
+
+func (r *fakeOutboxRepository) WithTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	report := *r.reportRepository.report
+	messages := make(map[primitive.ObjectID]*domain.Message)
+	for id, message := range r.messageRepository.messages {
+		messages[id] = message
+	}
+	err := fn(ctx)
+	if err == nil {
+		err = r.fail
+	}
+	if err != nil {
+		*r.reportRepository.report = report
+		r.messageRepository.messages = messages
+	}
+	return err
+}
+
+func newResolveService(targetType string) (Service, *fakeOutboxRepository, string) {
+	staffId := primitive.NewObjectID().Hex()
+	message := &domain.Message{Content: "spam"}
+	message.ID = primitive.NewObjectID()
+	report := &domain.Report{TargetType: targetType, TargetId: message.ID, Status: domain.REPORT_STATUS_OPEN}
+	report.ID = primitive.NewObjectID()
+
+	reportRepository := &fakeReportRepository{report: report}
+	messageRepository := &fakeMessageRepository{messages: map[primitive.ObjectID]*domain.Message{message.ID: message}}
+	outboxRepository := &fakeOutboxRepository{reportRepository: reportRepository, messageRepository: messageRepository}
+	service := New(reportRepository, nil, messageRepository, outboxRepository, nil, []string{staffId}, 0)
+	return service, outboxRepository, staffId
+}
+
+func TestResolveRemovesReportedMessage(t *testing.T) {
+	service, outboxRepository, staffId := newResolveService(domain.REPORT_TARGET_MESSAGE)
+	report := outboxRepository.reportRepository.report
+
+	err := service.Resolve(context.Background(), report.ID.Hex(), staffId, domain.ReportResolveRequest{Action: domain.REPORT_ACTION_CONTENT_REMOVE})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outboxRepository.messageRepository.messages) != 0 {
+		t.Error("reported message is still there")
+	}
+	if report.Status != domain.REPORT_STATUS_RESOLVED || report.Action != domain.REPORT_ACTION_CONTENT_REMOVE {
+		t.Errorf("report is %s with action %q", report.Status, report.Action)
+	}
+}
+
+func TestResolveKeepsMessageWhenResolutionFails(t *testing.T) {
+	service, outboxRepository, staffId := newResolveService(domain.REPORT_TARGET_MESSAGE)
+	outboxRepository.fail = errors.New("transaction aborted")
+	report := outboxRepository.reportRepository.report
+
+	err := service.Resolve(context.Background(), report.ID.Hex(), staffId, domain.ReportResolveRequest{Action: domain.REPORT_ACTION_CONTENT_REMOVE})
+	if err == nil {
+		t.Fatal("resolution did not fail")
+	}
+	if len(outboxRepository.messageRepository.messages) != 1 || report.Status != domain.REPORT_STATUS_OPEN {
+		t.Errorf("message removed or report %s although the resolution failed", report.Status)
+	}
+}
+
+func TestResolveRejectsContentRemovalOfMembers(t *testing.T) {
+	service, outboxRepository, staffId := newResolveService(domain.REPORT_TARGET_MEMBER)
+	report := outboxRepository.reportRepository.report
+
+	err := service.Resolve(context.Background(), report.ID.Hex(), staffId, domain.ReportResolveRequest{Action: domain.REPORT_ACTION_CONTENT_REMOVE})
+	var customErr *exceptions.Error
+	if !errors.As(err, &customErr) || customErr.Err != exceptions.ErrInvalidReportAction {
+		t.Fatalf("got %v, want ErrInvalidReportAction", err)
+	}
+	if report.Status != domain.REPORT_STATUS_OPEN {
+		t.Errorf("report is %s", report.Status)
+	}
+}
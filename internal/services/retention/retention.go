@@ -0,0 +1,77 @@
+package retention
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/messages"
+)
+
+type Service interface {
+	Purge(ctx context.Context) (int64, error)
+	Start(ctx context.Context)
+}
+
+type RetentionService struct {
+	channelRepository channels.Repository
+	messageRepository messages.Repository
+	interval          time.Duration
+}
+
+func New(channelRepository channels.Repository, messageRepository messages.Repository, interval time.Duration) Service {
+	return &RetentionService{
+		channelRepository,
+		messageRepository,
+		interval,
+	}
+}
+
+// Purge deletes the messages older than the retention of every channel that has one.
+func (h *RetentionService) Purge(ctx context.Context) (int64, error) {
+	channels, err := h.channelRepository.ListWithRetention(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	now := time.Now()
+	for _, channel := range channels {
+		cutoff, ok := channel.RetentionCutoff(now)
+		if !ok {
+			continue
+		}
+		deleted, err := h.messageRepository.DeleteOlderThan(ctx, channel.ID, cutoff)
+		if err != nil {
+			return total, err
+		}
+		total += deleted
+	}
+	return total, nil
+}
+
+// Start runs Purge on every interval until ctx is cancelled.
+func (h *RetentionService) Start(ctx context.Context) {
+	if h.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := h.Purge(ctx)
+			if err != nil {
+				log.Println("retention purge failed:", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("retention purge deleted %d messages", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package userevents
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/inbox"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/profiles"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/services/channels"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service interface {
+	Handle(ctx context.Context, value []byte) error
+}
+
+type UserEventService struct {
+	inboxRepository   inbox.Repository
+	profileRepository profiles.Repository
+	channelService    channels.Service
+}
+
+func New(inboxRepository inbox.Repository, profileRepository profiles.Repository, channelService channels.Service) Service {
+	return &UserEventService{
+		inboxRepository,
+		profileRepository,
+		channelService,
+	}
+}
+
+// Handle applies a user lifecycle event to the channels and the user profiles. Malformed events
+// are logged and skipped since retrying them cannot succeed, events already processed are ignored.
+func (h *UserEventService) Handle(ctx context.Context, value []byte) error {
+	var event domain.UserEvent
+	if err := json.Unmarshal(value, &event); err != nil {
+		log.Println("skipping malformed user event:", err)
+		return nil
+	}
+	if event.ID == "" {
+		log.Println("skipping user event without id")
+		return nil
+	}
+	userId, err := primitive.ObjectIDFromHex(event.UserId)
+	if err != nil {
+		log.Printf("skipping user event %s: invalid user id %q", event.ID, event.UserId)
+		return nil
+	}
+
+	processed, err := h.inboxRepository.IsProcessed(ctx, event.ID)
+	if err != nil {
+		return err
+	}
+	if processed {
+		return nil
+	}
+
+	switch event.Type {
+	case domain.USER_EVENT_CREATED, domain.USER_EVENT_UPDATED:
+		if event.User == nil {
+			log.Printf("skipping user event %s: missing user", event.ID)
+			return nil
+		}
+		event.User.ID = userId
+		err = h.profileRepository.Upsert(ctx, event.User.ToProfile(event.OccurredAt))
+	case domain.USER_EVENT_DELETED:
+		err = h.channelService.RemoveUser(ctx, event.UserId)
+		if err == nil {
+			err = h.profileRepository.Delete(ctx, userId, event.OccurredAt)
+		}
+	case domain.USER_EVENT_DENUNCIATED:
+		// the denunciation policy is applied when members are expanded, the user stays in the channels
+		err = h.profileRepository.MarkDenunciated(ctx, userId, event.OccurredAt)
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return h.inboxRepository.MarkProcessed(ctx, event.ID, event.Type)
+}
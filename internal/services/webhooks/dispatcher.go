@@ -0,0 +1,246 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/deliveries"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/webhooks"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/backoff"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/netguard"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	RETRY_BASE_DELAY = 10 * time.Second
+	RETRY_MAX_DELAY  = time.Hour
+	// CLAIM_MARGIN is how long a claimed delivery outlasts the request timeout, leaving time to record the outcome
+	CLAIM_MARGIN = time.Minute
+
+	HEADER_EVENT_ID  = "X-Webhook-Id"
+	HEADER_EVENT     = "X-Webhook-Event"
+	HEADER_TIMESTAMP = "X-Webhook-Timestamp"
+	HEADER_SIGNATURE = "X-Webhook-Signature"
+)
+
+// Dispatcher is the publisher feeding the webhooks. Publish enqueues a delivery per subscribed
+// webhook and Dispatch sends the due ones, as structured CloudEvents.
+type Dispatcher interface {
+	events.EventPublisher
+	Dispatch(ctx context.Context) (int, error)
+	Start(ctx context.Context)
+}
+
+type Config struct {
+	Interval     time.Duration
+	BatchSize    int64
+	Timeout      time.Duration
+	MaxAttempts  int
+	DisableAfter int
+	// AllowPrivateNetworks lets deliveries reach private and loopback addresses, for local development
+	AllowPrivateNetworks bool
+}
+
+type WebhookDispatcher struct {
+	webhookRepository  webhooks.Repository
+	deliveryRepository deliveries.Repository
+	encoder            *events.CloudEventEncoder
+	client             *http.Client
+	config             Config
+}
+
+func NewDispatcher(webhookRepository webhooks.Repository, deliveryRepository deliveries.Repository, encoder *events.CloudEventEncoder, config Config) Dispatcher {
+	return &WebhookDispatcher{
+		webhookRepository,
+		deliveryRepository,
+		encoder,
+		netguard.NewClient(config.Timeout, config.AllowPrivateNetworks),
+		config,
+	}
+}
+
+func (h *WebhookDispatcher) Publish(ctx context.Context, events ...events.Event) error {
+	for _, event := range events {
+		channelId, err := primitive.ObjectIDFromHex(event.ChannelId)
+		if err != nil {
+			return err
+		}
+		webhooks, err := h.webhookRepository.ListSubscribed(ctx, channelId, event.Type)
+		if err != nil {
+			return err
+		}
+		if len(webhooks) == 0 {
+			continue
+		}
+
+		payload, err := h.encoder.Structured(event)
+		if err != nil {
+			return err
+		}
+		pending := make([]*domain.WebhookDelivery, 0, len(webhooks))
+		for _, webhook := range webhooks {
+			pending = append(pending, &domain.WebhookDelivery{
+				WebhookId:     webhook.ID,
+				ChannelId:     channelId,
+				EventId:       event.ID,
+				EventType:     event.Type,
+				Payload:       string(payload),
+				Status:        domain.DELIVERY_STATUS_PENDING,
+				NextAttemptAt: time.Now(),
+			})
+		}
+		if err := h.deliveryRepository.Enqueue(ctx, pending...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *WebhookDispatcher) Close() error {
+	return nil
+}
+
+// Dispatch sends a batch of due deliveries, claiming them one by one so that every instance of the
+// service can dispatch without sending a delivery twice. A failed delivery is retried with backoff
+// until MaxAttempts, and a webhook is disabled once DisableAfter attempts failed in a row. The
+// deliveries of a disabled webhook stay pending until it is enabled again, the ones of a deleted
+// webhook fail.
+func (h *WebhookDispatcher) Dispatch(ctx context.Context) (int, error) {
+	loaded := make(map[primitive.ObjectID]*domain.Webhook)
+	delivered := 0
+	for claimed := int64(0); claimed < h.config.BatchSize; claimed++ {
+		now := time.Now()
+		delivery, err := h.deliveryRepository.ClaimDue(ctx, now, now.Add(h.config.Timeout+CLAIM_MARGIN))
+		if err != nil {
+			return delivered, err
+		}
+		if delivery == nil {
+			break
+		}
+
+		webhook, ok := loaded[delivery.WebhookId]
+		if !ok {
+			webhook, err = h.webhookRepository.Get(ctx, delivery.WebhookId)
+			if err != nil && !isNotFound(err) {
+				return delivered, err
+			}
+			loaded[delivery.WebhookId] = webhook
+		}
+		if webhook == nil {
+			cause := errors.New("webhook is deleted")
+			if err := h.deliveryRepository.MarkFailed(ctx, delivery.ID, domain.DELIVERY_STATUS_FAILED, now, 0, cause); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+		if !webhook.Enabled {
+			if err := h.deliveryRepository.Postpone(ctx, delivery.ID, now.Add(RETRY_MAX_DELAY)); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		responseStatus, err := h.send(ctx, webhook, delivery)
+		if err == nil {
+			if err := h.deliveryRepository.MarkDelivered(ctx, delivery.ID, responseStatus); err != nil {
+				return delivered, err
+			}
+			if err := h.webhookRepository.RecordSuccess(ctx, webhook.ID); err != nil {
+				return delivered, err
+			}
+			delivered++
+			continue
+		}
+
+		status := domain.DELIVERY_STATUS_PENDING
+		if delivery.Attempts+1 >= h.config.MaxAttempts {
+			status = domain.DELIVERY_STATUS_FAILED
+		}
+		if err := h.deliveryRepository.MarkFailed(ctx, delivery.ID, status, now.Add(backoff.Exponential(delivery.Attempts, RETRY_BASE_DELAY, RETRY_MAX_DELAY)), responseStatus, err); err != nil {
+			return delivered, err
+		}
+		disabled, err := h.webhookRepository.RecordFailure(ctx, webhook.ID, h.config.DisableAfter)
+		if err != nil {
+			return delivered, err
+		}
+		if disabled {
+			log.Printf("webhook %s disabled after %d failed deliveries", webhook.ID.Hex(), h.config.DisableAfter)
+			webhook.Enabled = false
+		}
+	}
+	return delivered, nil
+}
+
+// Start dispatches the due deliveries on every interval until ctx is cancelled.
+func (h *WebhookDispatcher) Start(ctx context.Context) {
+	if h.config.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := h.Dispatch(ctx); err != nil {
+				log.Println("webhook dispatch failed:", err)
+			}
+		}
+	}
+}
+
+// send posts the payload to the webhook, a response outside 2xx is a failure.
+func (h *WebhookDispatcher) send(ctx context.Context, webhook *domain.Webhook, delivery *domain.WebhookDelivery) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Content-Type", events.CLOUDEVENTS_CONTENT_TYPE)
+	request.Header.Set(HEADER_EVENT_ID, delivery.EventId)
+	request.Header.Set(HEADER_EVENT, delivery.EventType)
+	request.Header.Set(HEADER_TIMESTAMP, timestamp)
+	request.Header.Set(HEADER_SIGNATURE, "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	response, err := h.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected response status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
+
+// Sign returns the hex HMAC-SHA256 of "<timestamp>.<body>" keyed by the webhook secret.
+// Receivers recompute it to authenticate the delivery and reject stale timestamps.
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func isNotFound(err error) bool {
+	var customErr *exceptions.Error
+	return errors.As(err, &customErr) && customErr.Err == exceptions.ErrWebhookNotFound
+}
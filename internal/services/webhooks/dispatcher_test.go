@@ -0,0 +1,321 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/deliveries"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/webhooks"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/netguard"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakeWebhookRepository struct {
+	webhooks.Repository
+	mutex     sync.Mutex
+	webhook   *domain.Webhook
+	successes int
+	failures  int
+}
+
+func (r *fakeWebhookRepository) Get(ctx context.Context, id primitive.ObjectID) (*domain.Webhook, error) {
+	return r.webhook, nil
+}
+
+func (r *fakeWebhookRepository) RecordSuccess(ctx context.Context, id primitive.ObjectID) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.successes++
+	return nil
+}
+
+func (r *fakeWebhookRepository) RecordFailure(ctx context.Context, id primitive.ObjectID, disableAfter int) (bool, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.failures++
+	return false, nil
+}
+
+// fakeDeliveryRepository claims the due deliveries like the MongoDB repository, pushing their
+// next attempt to the end of the claim.
+type fakeDeliveryRepository struct {
+	deliveries.Repository
+	mutex sync.Mutex
+	due   []*domain.WebhookDelivery
+}
+
+func (r *fakeDeliveryRepository) ClaimDue(ctx context.Context, now time.Time, claimUntil time.Time) (*domain.WebhookDelivery, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	for _, delivery := range r.due {
+		if delivery.Status == domain.DELIVERY_STATUS_PENDING && !delivery.NextAttemptAt.After(now) {
+			delivery.NextAttemptAt = claimUntil
+			claimed := *delivery
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeDeliveryRepository) find(id primitive.ObjectID) *domain.WebhookDelivery {
+	for _, delivery := range r.due {
+		if delivery.ID == id {
+			return delivery
+		}
+	}
+	return nil
+}
+
+func (r *fakeDeliveryRepository) MarkDelivered(ctx context.Context, id primitive.ObjectID, responseStatus int) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delivery := r.find(id)
+	now := time.Now()
+	delivery.Status = domain.DELIVERY_STATUS_DELIVERED
+	delivery.ResponseStatus = responseStatus
+	delivery.DeliveredAt = &now
+	return nil
+}
+
+func (r *fakeDeliveryRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, status string, nextAttemptAt time.Time, responseStatus int, cause error) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delivery := r.find(id)
+	delivery.Status = status
+	delivery.Attempts++
+	delivery.NextAttemptAt = nextAttemptAt
+	delivery.ResponseStatus = responseStatus
+	delivery.LastError = cause.Error()
+	return nil
+}
+
+func (r *fakeDeliveryRepository) Postpone(ctx context.Context, id primitive.ObjectID, nextAttemptAt time.Time) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.find(id).NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func newTestDispatcher(url string, allowPrivate bool) (*WebhookDispatcher, *fakeWebhookRepository, *fakeDeliveryRepository) {
+	webhook := &domain.Webhook{URL: url, Secret: "topsecret", Enabled: true}
+	webhook.ID = primitive.NewObjectID()
+	delivery := &domain.WebhookDelivery{
+		WebhookId: webhook.ID,
+		EventId:   "event-1",
+		EventType: events.CHANNEL_UPDATED,
+		Payload:   `{"id":"event-1"}`,
+		Status:    domain.DELIVERY_STATUS_PENDING,
+	}
+	delivery.ID = primitive.NewObjectID()
+
+	webhookRepository := &fakeWebhookRepository{webhook: webhook}
+	deliveryRepository := &fakeDeliveryRepository{due: []*domain.WebhookDelivery{delivery}}
+	dispatcher := NewDispatcher(webhookRepository, deliveryRepository, nil, Config{
+		BatchSize:            10,
+		Timeout:              time.Second,
+		MaxAttempts:          3,
+		DisableAfter:         10,
+		AllowPrivateNetworks: allowPrivate,
+	}).(*WebhookDispatcher)
+	return dispatcher, webhookRepository, deliveryRepository
+}
+
+func TestSign(t *testing.T) {
+	// computed independently as HMAC-SHA256("topsecret", `1700000000.{"id":"1"}`)
+	want := "5ae2fe9589b5395efc54aa255a5cd86f4f6884285427ae96ebfc427963e60e81"
+	if got := Sign("topsecret", "1700000000", []byte(`{"id":"1"}`)); got != want {
+		t.Errorf("Sign() = %s, want %s", got, want)
+	}
+	if Sign("other", "1700000000", []byte(`{"id":"1"}`)) == want {
+		t.Error("signature does not depend on the secret")
+	}
+	if Sign("topsecret", "1700000001", []byte(`{"id":"1"}`)) == want {
+		t.Error("signature does not depend on the timestamp")
+	}
+}
+
+func TestDispatchSendsSignedDelivery(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	dispatcher, webhookRepository, deliveryRepository := newTestDispatcher(server.URL, true)
+	delivered, err := dispatcher.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 1 {
+		t.Fatalf("delivered %d, want 1", delivered)
+	}
+
+	if received.Method != http.MethodPost {
+		t.Errorf("method %s, want POST", received.Method)
+	}
+	if received.Header.Get("Content-Type") != events.CLOUDEVENTS_CONTENT_TYPE {
+		t.Errorf("content type %q", received.Header.Get("Content-Type"))
+	}
+	if received.Header.Get(HEADER_EVENT_ID) != "event-1" || received.Header.Get(HEADER_EVENT) != events.CHANNEL_UPDATED {
+		t.Errorf("event headers %q %q", received.Header.Get(HEADER_EVENT_ID), received.Header.Get(HEADER_EVENT))
+	}
+	if string(body) != `{"id":"event-1"}` {
+		t.Errorf("body %s", body)
+	}
+
+	timestamp := received.Header.Get(HEADER_TIMESTAMP)
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(seconds, 0)) > time.Minute {
+		t.Errorf("timestamp %q is not current", timestamp)
+	}
+	signature, found := strings.CutPrefix(received.Header.Get(HEADER_SIGNATURE), "sha256=")
+	if !found || signature != Sign("topsecret", timestamp, body) {
+		t.Errorf("signature %q does not verify", received.Header.Get(HEADER_SIGNATURE))
+	}
+
+	delivery := deliveryRepository.due[0]
+	if delivery.Status != domain.DELIVERY_STATUS_DELIVERED || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("delivery is %s with status %d", delivery.Status, delivery.ResponseStatus)
+	}
+	if webhookRepository.successes != 1 {
+		t.Errorf("recorded %d successes, want 1", webhookRepository.successes)
+	}
+}
+
+func TestDispatchRetriesFailedDelivery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	dispatcher, webhookRepository, deliveryRepository := newTestDispatcher(server.URL, true)
+	before := time.Now()
+	delivered, err := dispatcher.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delivered != 0 {
+		t.Fatalf("delivered %d, want 0", delivered)
+	}
+
+	delivery := deliveryRepository.due[0]
+	if delivery.Status != domain.DELIVERY_STATUS_PENDING || delivery.ResponseStatus != http.StatusServiceUnavailable {
+		t.Errorf("delivery is %s with status %d", delivery.Status, delivery.ResponseStatus)
+	}
+	if delivery.NextAttemptAt.Before(before.Add(RETRY_BASE_DELAY)) {
+		t.Errorf("delivery is retried at %v, want a backoff", delivery.NextAttemptAt)
+	}
+	if webhookRepository.failures != 1 {
+		t.Errorf("recorded %d failures, want 1", webhookRepository.failures)
+	}
+
+	// the last attempt fails the delivery for good
+	delivery.Attempts = 2
+	delivery.NextAttemptAt = time.Now()
+	if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if delivery.Status != domain.DELIVERY_STATUS_FAILED {
+		t.Errorf("delivery out of attempts is %s", delivery.Status)
+	}
+}
+
+func TestDispatchPostponesDeliveriesOfDisabledWebhook(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	dispatcher, webhookRepository, deliveryRepository := newTestDispatcher(server.URL, true)
+	webhookRepository.webhook.Enabled = false
+	if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	delivery := deliveryRepository.due[0]
+	if requests != 0 {
+		t.Errorf("disabled webhook received %d requests", requests)
+	}
+	if delivery.Status != domain.DELIVERY_STATUS_PENDING || delivery.Attempts != 0 {
+		t.Errorf("delivery is %s after %d attempts, want pending", delivery.Status, delivery.Attempts)
+	}
+	if !delivery.NextAttemptAt.After(time.Now()) {
+		t.Error("delivery is not postponed")
+	}
+}
+
+func TestDispatchRefusesPrivateAddresses(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer server.Close()
+
+	dispatcher, _, deliveryRepository := newTestDispatcher(server.URL, false)
+	if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if requests != 0 {
+		t.Errorf("loopback server received %d requests", requests)
+	}
+	delivery := deliveryRepository.due[0]
+	if !strings.Contains(delivery.LastError, netguard.ErrForbiddenAddress.Error()) {
+		t.Errorf("delivery failed with %q, want %v", delivery.LastError, netguard.ErrForbiddenAddress)
+	}
+}
+
+func TestConcurrentDispatchersSendEachDeliveryOnce(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(time.Millisecond)
+	}))
+	defer server.Close()
+
+	dispatcher, webhookRepository, deliveryRepository := newTestDispatcher(server.URL, true)
+	for i := 0; i < 9; i++ {
+		delivery := *deliveryRepository.due[0]
+		delivery.ID = primitive.NewObjectID()
+		deliveryRepository.due = append(deliveryRepository.due, &delivery)
+	}
+	other := NewDispatcher(webhookRepository, deliveryRepository, nil, dispatcher.config).(*WebhookDispatcher)
+
+	var wait sync.WaitGroup
+	var delivered atomic.Int32
+	for _, instance := range []*WebhookDispatcher{dispatcher, other} {
+		wait.Add(1)
+		go func(instance *WebhookDispatcher) {
+			defer wait.Done()
+			count, err := instance.Dispatch(context.Background())
+			if err != nil {
+				t.Error(err)
+			}
+			delivered.Add(int32(count))
+		}(instance)
+	}
+	wait.Wait()
+
+	if requests.Load() != 10 || delivered.Load() != 10 {
+		t.Errorf("sent %d requests for %d deliveries, want 10", requests.Load(), delivered.Load())
+	}
+	for _, delivery := range deliveryRepository.due {
+		if delivery.Status != domain.DELIVERY_STATUS_DELIVERED {
+			t.Errorf("delivery %s is %s", delivery.ID.Hex(), delivery.Status)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/ADAGroupTcc/ms-channels-api/exceptions"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/domain"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/events"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/helpers"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/channels"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/deliveries"
+	"github.com/ADAGroupTcc/ms-channels-api/internal/repositories/webhooks"
+	"github.com/ADAGroupTcc/ms-channels-api/pkg/netguard"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Service interface {
+	Create(ctx context.Context, channelId string, actorId string, request domain.WebhookRequest) (*domain.Webhook, error)
+	List(ctx context.Context, channelId string, actorId string) (*domain.WebhooksResponse, error)
+	Update(ctx context.Context, channelId string, id string, actorId string, request domain.WebhookPatchRequest) (*domain.Webhook, error)
+	Delete(ctx context.Context, channelId string, id string, actorId string) error
+	ListDeliveries(ctx context.Context, channelId string, id string, queryParams helpers.QueryParams) (*domain.WebhookDeliveryResponse, error)
+}
+
+type WebhookService struct {
+	webhookRepository  webhooks.Repository
+	deliveryRepository deliveries.Repository
+	channelRepository  channels.Repository
+	allowInsecureUrls  bool
+}
+
+// New builds the service, allowInsecureUrls accepts plain http and private hosts for local development.
+func New(webhookRepository webhooks.Repository, deliveryRepository deliveries.Repository, channelRepository channels.Repository, allowInsecureUrls bool) Service {
+	return &WebhookService{
+		webhookRepository,
+		deliveryRepository,
+		channelRepository,
+		allowInsecureUrls,
+	}
+}
+
+func (h *WebhookService) Create(ctx context.Context, channelId string, actorId string, request domain.WebhookRequest) (*domain.Webhook, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if err := validateEventTypes(request.EventTypes); err != nil {
+		return nil, err
+	}
+	if err := h.checkUrl(ctx, request.URL); err != nil {
+		return nil, err
+	}
+
+	channel, parsedActorId, err := h.getAsAdmin(ctx, channelId, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.webhookRepository.Create(ctx, request.ToWebhook(channel.ID, parsedActorId))
+}
+
+func (h *WebhookService) List(ctx context.Context, channelId string, actorId string) (*domain.WebhooksResponse, error) {
+	channel, _, err := h.getAsAdmin(ctx, channelId, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	webhooks, err := h.webhookRepository.ListByChannel(ctx, channel.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.WebhooksResponse{Webhooks: webhooks}, nil
+}
+
+func (h *WebhookService) Update(ctx context.Context, channelId string, id string, actorId string, request domain.WebhookPatchRequest) (*domain.Webhook, error) {
+	err := request.Validate()
+	if err != nil {
+		return nil, err
+	}
+	if request.EventTypes != nil {
+		if err := validateEventTypes(*request.EventTypes); err != nil {
+			return nil, err
+		}
+	}
+	if request.URL != nil {
+		if err := h.checkUrl(ctx, *request.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	webhook, err := h.getWebhook(ctx, channelId, id, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.webhookRepository.Update(ctx, webhook.ID, request.ToBsonM())
+	if err != nil {
+		return nil, err
+	}
+	// the deliveries postponed while the webhook was disabled are sent right away
+	if request.Enabled != nil && *request.Enabled && !webhook.Enabled {
+		err = h.deliveryRepository.Resume(ctx, webhook.ID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+	return h.webhookRepository.Get(ctx, webhook.ID)
+}
+
+func (h *WebhookService) Delete(ctx context.Context, channelId string, id string, actorId string) error {
+	webhook, err := h.getWebhook(ctx, channelId, id, actorId)
+	if err != nil {
+		return err
+	}
+	return h.webhookRepository.Delete(ctx, webhook.ID)
+}
+
+func (h *WebhookService) ListDeliveries(ctx context.Context, channelId string, id string, queryParams helpers.QueryParams) (*domain.WebhookDeliveryResponse, error) {
+	if queryParams.Status != "" && !domain.IsValidDeliveryStatus(queryParams.Status) {
+		return nil, exceptions.New(exceptions.ErrInvalidDeliveryStatus, nil)
+	}
+
+	webhook, err := h.getWebhook(ctx, channelId, id, queryParams.HeaderUserId)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries, err := h.deliveryRepository.List(ctx, webhook.ID, queryParams.Status, queryParams.Limit, queryParams.Offset)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.WebhookDeliveryResponse{
+		Deliveries: deliveries,
+	}
+	if len(deliveries) == int(queryParams.Limit) {
+		response.NextPage = queryParams.Offset + 1
+	}
+	return response, nil
+}
+
+// getWebhook loads the webhook as an admin of the channel it belongs to.
+func (h *WebhookService) getWebhook(ctx context.Context, channelId string, id string, actorId string) (*domain.Webhook, error) {
+	parsedId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+
+	channel, _, err := h.getAsAdmin(ctx, channelId, actorId)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook, err := h.webhookRepository.Get(ctx, parsedId)
+	if err != nil {
+		return nil, err
+	}
+	if webhook.ChannelId != channel.ID {
+		return nil, exceptions.New(exceptions.ErrWebhookNotFound, nil)
+	}
+	return webhook, nil
+}
+
+func (h *WebhookService) getAsAdmin(ctx context.Context, channelId string, actorId string) (*domain.Channel, primitive.ObjectID, error) {
+	parsedChannelId, err := primitive.ObjectIDFromHex(channelId)
+	if err != nil {
+		return nil, primitive.NilObjectID, exceptions.New(exceptions.ErrInvalidID, err)
+	}
+	parsedActorId, err := primitive.ObjectIDFromHex(actorId)
+	if err != nil {
+		return nil, primitive.NilObjectID, exceptions.New(exceptions.ErrInvalidUserIdSent, err)
+	}
+
+	channel, err := h.channelRepository.Get(ctx, parsedChannelId)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+	if !channel.IsAdmin(parsedActorId) {
+		return nil, primitive.NilObjectID, exceptions.New(exceptions.ErrUserIsNotAdmin, nil)
+	}
+	return channel, parsedActorId, nil
+}
+
+func validateEventTypes(eventTypes []string) error {
+	for _, eventType := range eventTypes {
+		if !events.IsValidType(eventType) {
+			return exceptions.New(exceptions.ErrInvalidEventTypesField, nil)
+		}
+	}
+	return nil
+}
+
+// checkUrl requires https and a host resolving to public addresses only, so webhooks cannot be
+// pointed at the internal network. The dispatcher checks the address again when connecting.
+func (h *WebhookService) checkUrl(ctx context.Context, rawUrl string) error {
+	if h.allowInsecureUrls {
+		return nil
+	}
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return exceptions.New(exceptions.ErrInvalidUrlField, err)
+	}
+	if parsed.Scheme != "https" {
+		return exceptions.New(exceptions.ErrInvalidUrlField, errors.New("https is required"))
+	}
+	if err := netguard.CheckHost(ctx, parsed.Hostname()); err != nil {
+		return exceptions.New(exceptions.ErrInvalidUrlField, err)
+	}
+	return nil
+}
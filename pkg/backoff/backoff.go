@@ -0,0 +1,16 @@
+package backoff
+
+import "time"
+
+// Exponential returns the delay before retrying after attempts failed attempts: base, doubled on
+// every further attempt, up to max.
+func Exponential(attempts int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,24 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponential(t *testing.T) {
+	cases := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, time.Second},
+		{1, 2 * time.Second},
+		{3, 8 * time.Second},
+		{8, time.Minute},
+		{1000, time.Minute},
+	}
+	for _, c := range cases {
+		if got := Exponential(c.attempts, time.Second, time.Minute); got != c.want {
+			t.Errorf("Exponential(%d) = %v, want %v", c.attempts, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+const (
+	STATE_CLOSED    = "closed"
+	STATE_OPEN      = "open"
+	STATE_HALF_OPEN = "half_open"
+)
+
+// Breaker stops calling a failing dependency. It opens after threshold failures in a row,
+// rejects the calls with ErrOpen during the cooldown, then lets a single trial call through:
+// its success closes the breaker again and its failure reopens it for another cooldown.
+type Breaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+}
+
+func New(threshold int, cooldown time.Duration) *Breaker {
+	return &Breaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     STATE_CLOSED,
+	}
+}
+
+// Do runs fn unless the breaker is open, every error returned by fn counts as a failure.
+func (b *Breaker) Do(fn func() error) error {
+	if !b.allow() {
+		return ErrOpen
+	}
+	err := fn()
+	b.record(err == nil)
+	return err
+}
+
+func (b *Breaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case STATE_OPEN:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = STATE_HALF_OPEN
+		return true
+	case STATE_HALF_OPEN:
+		// the trial call is still running
+		return false
+	default:
+		return true
+	}
+}
+
+func (b *Breaker) record(success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if success {
+		b.state = STATE_CLOSED
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == STATE_HALF_OPEN || b.failures >= b.threshold {
+		b.state = STATE_OPEN
+		b.openedAt = time.Now()
+	}
+}
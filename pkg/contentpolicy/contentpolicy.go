@@ -0,0 +1,182 @@
+package contentpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	ACTION_REJECT = "reject"
+	ACTION_MASK   = "mask"
+	ACTION_FLAG   = "flag"
+)
+
+const MASK_CHARACTER = "*"
+
+// Rule matches whole words case-insensitively and arbitrary regular expressions,
+// applying its action to the text when any of them is found. A word only matches when it is
+// not surrounded by letters, marks or digits of any script.
+type Rule struct {
+	Name     string   `json:"name"`
+	Words    []string `json:"words"`
+	Patterns []string `json:"patterns"`
+	Action   string   `json:"action"`
+}
+
+type File struct {
+	Rules []Rule `json:"rules"`
+}
+
+type Result struct {
+	// Text is the checked text with the masked matches replaced
+	Text     string
+	Rejected bool
+	Flagged  bool
+	// Rules holds the names of every rule that matched
+	Rules []string
+}
+
+type Policy interface {
+	Check(text string) Result
+}
+
+type compiledRule struct {
+	name        string
+	action      string
+	expressions []expression
+}
+
+// expression is a compiled pattern, or a word whose matches must stand at word boundaries.
+// RE2 has no Unicode aware \b nor lookarounds, so the boundaries of words are checked on the
+// runes around each match instead.
+type expression struct {
+	regexp *regexp.Regexp
+	word   bool
+}
+
+type policy struct {
+	rules []compiledRule
+}
+
+// Load reads the rules from a JSON file, an empty path returns a policy that allows everything.
+func Load(path string) (Policy, error) {
+	if path == "" {
+		return New(nil)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file File
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+	return New(file.Rules)
+}
+
+func New(rules []Rule) (Policy, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Action != ACTION_REJECT && rule.Action != ACTION_MASK && rule.Action != ACTION_FLAG {
+			return nil, fmt.Errorf("content policy rule %q: invalid action %q", rule.Name, rule.Action)
+		}
+
+		expressions := make([]expression, 0, len(rule.Words)+len(rule.Patterns))
+		for _, word := range rule.Words {
+			if word == "" {
+				return nil, fmt.Errorf("content policy rule %q: empty word", rule.Name)
+			}
+			expressions = append(expressions, expression{regexp.MustCompile(`(?i)` + regexp.QuoteMeta(word)), true})
+		}
+		for _, pattern := range rule.Patterns {
+			compiledPattern, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("content policy rule %q: %w", rule.Name, err)
+			}
+			expressions = append(expressions, expression{compiledPattern, false})
+		}
+
+		compiled = append(compiled, compiledRule{rule.Name, rule.Action, expressions})
+	}
+	return &policy{compiled}, nil
+}
+
+func (p *policy) Check(text string) Result {
+	result := Result{Text: text}
+	for _, rule := range p.rules {
+		matched := false
+		for _, expression := range rule.expressions {
+			matches := expression.find(result.Text)
+			if len(matches) == 0 {
+				continue
+			}
+			matched = true
+			if rule.action == ACTION_MASK {
+				result.Text = mask(result.Text, matches)
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		result.Rules = append(result.Rules, rule.name)
+		switch rule.action {
+		case ACTION_REJECT:
+			result.Rejected = true
+		case ACTION_FLAG:
+			result.Flagged = true
+		}
+	}
+	return result
+}
+
+// find returns the start and end of every match in the text.
+func (e expression) find(text string) [][]int {
+	if !e.word {
+		return e.regexp.FindAllStringIndex(text, -1)
+	}
+
+	matches := make([][]int, 0)
+	for position := 0; position < len(text); {
+		match := e.regexp.FindStringIndex(text[position:])
+		if match == nil {
+			break
+		}
+		start, end := position+match[0], position+match[1]
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			matches = append(matches, []int{start, end})
+			position = end
+			continue
+		}
+		// an overlapping match may still stand at boundaries, resume after the first rune
+		_, size := utf8.DecodeRuneInString(text[start:])
+		position = start + size
+	}
+	return matches
+}
+
+func isWordRune(r rune) bool {
+	return unicode.In(r, unicode.L, unicode.M, unicode.N)
+}
+
+// mask replaces every rune of the matches with MASK_CHARACTER.
+func mask(text string, matches [][]int) string {
+	var masked strings.Builder
+	previous := 0
+	for _, match := range matches {
+		masked.WriteString(text[previous:match[0]])
+		masked.WriteString(strings.Repeat(MASK_CHARACTER, utf8.RuneCountInString(text[match[0]:match[1]])))
+		previous = match[1]
+	}
+	masked.WriteString(text[previous:])
+	return masked.String()
+}
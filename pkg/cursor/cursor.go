@@ -0,0 +1,61 @@
+package cursor
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrInvalid = errors.New("invalid cursor")
+
+var encoding = base64.RawURLEncoding
+
+// Signer turns a position into an opaque token clients hand back as is. The position is BSON
+// encoded so its values keep their types, and signed so clients cannot forge or alter it.
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret}
+}
+
+func (s *Signer) Encode(position interface{}) (string, error) {
+	payload, err := bson.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(payload) + "." + encoding.EncodeToString(s.sign(payload)), nil
+}
+
+func (s *Signer) Decode(token string, position interface{}) error {
+	encodedPayload, encodedSignature, found := strings.Cut(token, ".")
+	if !found {
+		return ErrInvalid
+	}
+	payload, err := encoding.DecodeString(encodedPayload)
+	if err != nil {
+		return ErrInvalid
+	}
+	signature, err := encoding.DecodeString(encodedSignature)
+	if err != nil {
+		return ErrInvalid
+	}
+	if !hmac.Equal(signature, s.sign(payload)) {
+		return ErrInvalid
+	}
+	if err := bson.Unmarshal(payload, position); err != nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+func (s *Signer) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
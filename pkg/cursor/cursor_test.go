@@ -0,0 +1,67 @@
+package cursor
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type position struct {
+	Sort   string        `bson:"sort"`
+	Values []interface{} `bson:"values"`
+}
+
+func TestEncodeDecode(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	token, err := signer.Encode(position{Sort: "name:asc", Values: []interface{}{"general", int32(3)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded position
+	if err := signer.Decode(token, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Sort != "name:asc" || len(decoded.Values) != 2 || decoded.Values[0] != "general" || decoded.Values[1] != int32(3) {
+		t.Errorf("decoded %+v", decoded)
+	}
+}
+
+func TestDecodeRejectsAlteredTokens(t *testing.T) {
+	signer := NewSigner([]byte("secret"))
+	token, err := signer.Encode(position{Sort: "name:asc", Values: []interface{}{"general"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(token, ".")
+	forged, err := NewSigner([]byte("other")).Encode(position{Sort: "name:asc", Values: []interface{}{"admin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedPayload, _, _ := strings.Cut(forged, ".")
+
+	flipped := []byte(payload)
+	if flipped[0] == 'A' {
+		flipped[0] = 'B'
+	} else {
+		flipped[0] = 'A'
+	}
+
+	cases := map[string]string{
+		"empty":              "",
+		"without signature":  payload,
+		"altered payload":    string(flipped) + "." + signature,
+		"swapped payload":    forgedPayload + "." + signature,
+		"signed by other":    forged,
+		"truncated":          token[:len(token)-2],
+		"not base64":         "!!." + signature,
+		"signature not b64":  payload + ".!!",
+		"appended signature": token + "A",
+	}
+	for name, tampered := range cases {
+		var decoded position
+		if err := signer.Decode(tampered, &decoded); !errors.Is(err, ErrInvalid) {
+			t.Errorf("%s: got %v, want ErrInvalid", name, err)
+		}
+	}
+}
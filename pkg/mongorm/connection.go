@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -25,3 +26,30 @@ func Connect(uri string, clusterName string) (*mongo.Database, error) {
 	fmt.Println("Successfully connected to MongoDB")
 	return client.Database(clusterName), nil
 }
+
+// SupportsTransactions tells whether the deployment is a replica set or a sharded cluster,
+// a standalone server has no sessions to run transactions in.
+func SupportsTransactions(ctx context.Context, db *mongo.Database) (bool, error) {
+	var hello bson.M
+	err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello)
+	if err != nil {
+		return false, err
+	}
+	_, replicaSet := hello["setName"]
+	return replicaSet || hello["msg"] == "isdbgrid", nil
+}
+
+// WithTransaction runs fn inside a transaction, every operation using the context given to fn takes part in it.
+// Transactions require MongoDB to run as a replica set.
+func WithTransaction(ctx context.Context, db *mongo.Database, fn func(ctx context.Context) error) error {
+	session, err := db.Client().StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessionCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessionCtx)
+	})
+	return err
+}
@@ -0,0 +1,101 @@
+package mongorm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// LEASE_TTL is how long a lease is held without being renewed, its holder renews it every third of it.
+const LEASE_TTL = 30 * time.Second
+
+var ErrLeaseLost = errors.New("lease lost")
+
+// Lease elects a single instance of the service to run a task, such as watching a collection.
+// Leases are documents of leaseCollection keyed by the name of the task.
+type Lease struct {
+	db              *mongo.Database
+	leaseCollection string
+	name            string
+	owner           string
+}
+
+func NewLease(db *mongo.Database, leaseCollection string, name string) *Lease {
+	return &Lease{db, leaseCollection, name, primitive.NewObjectID().Hex()}
+}
+
+// Hold runs fn while this instance holds the lease, renewing it meanwhile. It reports false without
+// running fn when another instance holds it. The context of fn is cancelled when the lease is lost,
+// fn then returning makes Hold return ErrLeaseLost. The lease is released once fn returns.
+func (l *Lease) Hold(ctx context.Context, fn func(ctx context.Context) error) (bool, error) {
+	held, err := l.acquire(ctx)
+	if !held {
+		return false, err
+	}
+	defer l.release()
+
+	holdCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		ticker := time.NewTicker(LEASE_TTL / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-holdCtx.Done():
+				return
+			case <-ticker.C:
+				held, err := l.acquire(holdCtx)
+				if holdCtx.Err() != nil {
+					return
+				}
+				if !held {
+					log.Printf("lease %s lost: %v", l.name, err)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+
+	err = fn(holdCtx)
+	if ctx.Err() == nil && holdCtx.Err() != nil {
+		return true, ErrLeaseLost
+	}
+	return true, err
+}
+
+// acquire takes or renews the lease, telling whether this instance holds it. The lease is matched
+// only when it is ours or expired, so when another instance holds it the upsert collides with it
+// on the _id instead.
+func (l *Lease) acquire(ctx context.Context) (bool, error) {
+	now := time.Now()
+	filter := bson.M{
+		"_id": l.name,
+		"$or": []bson.M{{"owner": l.owner}, {"expires_at": bson.M{"$lte": now}}},
+	}
+	update := bson.M{"$set": bson.M{"owner": l.owner, "expires_at": now.Add(LEASE_TTL)}}
+	_, err := l.db.Collection(l.leaseCollection).UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// release lets another instance take over without waiting for the lease to expire.
+func (l *Lease) release() {
+	ctx, cancel := context.WithTimeout(context.Background(), LEASE_TTL/3)
+	defer cancel()
+	_, err := l.db.Collection(l.leaseCollection).DeleteOne(ctx, bson.M{"_id": l.name, "owner": l.owner})
+	if err != nil {
+		log.Printf("lease %s could not be released: %v", l.name, err)
+	}
+}
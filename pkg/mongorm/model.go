@@ -110,3 +110,50 @@ func (m *Model) Delete(ctx context.Context, db *mongo.Database, collectionName s
 
 	return nil
 }
+
+func DeleteMany(ctx context.Context, db *mongo.Database, collectionName string, filter interface{}, opts ...*options.DeleteOptions) (int64, error) {
+	collection := db.Collection(collectionName)
+	res, err := collection.DeleteMany(ctx, filter, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.DeletedCount, nil
+}
+
+func CreateIndexes(ctx context.Context, db *mongo.Database, collectionName string, models []mongo.IndexModel, opts ...*options.CreateIndexesOptions) error {
+	collection := db.Collection(collectionName)
+	_, err := collection.Indexes().CreateMany(ctx, models, opts...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func Count(ctx context.Context, db *mongo.Database, collectionName string, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	collection := db.Collection(collectionName)
+	return collection.CountDocuments(ctx, filter, opts...)
+}
+
+func Distinct(ctx context.Context, db *mongo.Database, collectionName string, fieldName string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	collection := db.Collection(collectionName)
+	return collection.Distinct(ctx, fieldName, filter, opts...)
+}
+
+func UpdateMany(ctx context.Context, db *mongo.Database, collectionName string, filter interface{}, update interface{}, opts ...*options.UpdateOptions) (int64, error) {
+	collection := db.Collection(collectionName)
+	res, err := collection.UpdateMany(ctx, filter, update, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// FindOneAndUpdate updates the first document matching the filter and decodes it into result,
+// it returns mongo.ErrNoDocuments when none matches.
+func FindOneAndUpdate(ctx context.Context, db *mongo.Database, collectionName string, filter interface{}, update interface{}, result interface{}, opts ...*options.FindOneAndUpdateOptions) error {
+	collection := db.Collection(collectionName)
+	return collection.FindOneAndUpdate(ctx, filter, update, opts...).Decode(result)
+}
@@ -0,0 +1,156 @@
+package mongorm
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	CHANGE_INSERT  = "insert"
+	CHANGE_UPDATE  = "update"
+	CHANGE_REPLACE = "replace"
+	CHANGE_DELETE  = "delete"
+
+	WATCHER_RETRY_DELAY = 5 * time.Second
+
+	// ERROR_CHANGE_STREAM_HISTORY_LOST is reported when the resume token fell off the oplog.
+	ERROR_CHANGE_STREAM_HISTORY_LOST = 286
+)
+
+// ChangeEvent is a document change read from a change stream. FullDocumentBeforeChange is only
+// filled when pre-images are enabled on the collection, TxnNumber when the change was part of a
+// multi-document transaction.
+type ChangeEvent struct {
+	ResumeToken              bson.Raw `bson:"_id"`
+	OperationType            string   `bson:"operationType"`
+	DocumentKey              bson.Raw `bson:"documentKey"`
+	FullDocument             bson.Raw `bson:"fullDocument,omitempty"`
+	FullDocumentBeforeChange bson.Raw `bson:"fullDocumentBeforeChange,omitempty"`
+	TxnNumber                *int64   `bson:"txnNumber,omitempty"`
+}
+
+type ChangeHandler func(ctx context.Context, change ChangeEvent) error
+
+// Watcher follows the inserts, updates, replaces and deletes of a collection. The resume token
+// is persisted after each handled change, so a restarted watcher continues where it stopped and
+// a change whose handler failed is read again. Only the instance holding the lease of the
+// collection watches it, the others wait to take over when the lease expires.
+type Watcher struct {
+	db              *mongo.Database
+	collectionName  string
+	tokenCollection string
+	lease           *Lease
+	handler         ChangeHandler
+}
+
+type resumeToken struct {
+	ID        string    `bson:"_id"`
+	Token     bson.Raw  `bson:"token"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+// NewWatcher watches collectionName, storing its resume token in tokenCollection and its lease in
+// leaseCollection under the collection name. Change streams require MongoDB to run as a replica set.
+func NewWatcher(db *mongo.Database, collectionName string, tokenCollection string, leaseCollection string, handler ChangeHandler) *Watcher {
+	return &Watcher{db, collectionName, tokenCollection, NewLease(db, leaseCollection, collectionName), handler}
+}
+
+// Start watches until ctx is cancelled while holding the lease, reopening the stream from the last
+// token on failures. A lost history is logged and the stream restarts from the current changes.
+func (w *Watcher) Start(ctx context.Context) {
+	for {
+		held, err := w.lease.Hold(ctx, w.watch)
+		if ctx.Err() != nil {
+			return
+		}
+		if !held && err != nil {
+			log.Printf("watcher of %s could not acquire its lease: %v", w.collectionName, err)
+		}
+		if held {
+			if isHistoryLost(err) {
+				log.Printf("ERROR: watcher of %s lost the change stream history, changes were missed and it restarts from now: %v", w.collectionName, err)
+				err = w.deleteToken(ctx)
+			}
+			if err != nil {
+				log.Printf("watcher of %s stopped: %v", w.collectionName, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(WATCHER_RETRY_DELAY):
+		}
+	}
+}
+
+func (w *Watcher) watch(ctx context.Context) error {
+	token, err := w.loadToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts := options.ChangeStream().
+		SetFullDocument(options.UpdateLookup).
+		SetFullDocumentBeforeChange(options.WhenAvailable)
+	if token != nil {
+		opts.SetStartAfter(token)
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": []string{CHANGE_INSERT, CHANGE_UPDATE, CHANGE_REPLACE, CHANGE_DELETE}}}}},
+	}
+
+	stream, err := w.db.Collection(w.collectionName).Watch(ctx, pipeline, opts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		var change ChangeEvent
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+		if err := w.handler(ctx, change); err != nil {
+			return err
+		}
+		if err := w.saveToken(ctx, stream.ResumeToken()); err != nil {
+			return err
+		}
+	}
+	return stream.Err()
+}
+
+func (w *Watcher) loadToken(ctx context.Context) (bson.Raw, error) {
+	var stored resumeToken
+	err := w.db.Collection(w.tokenCollection).FindOne(ctx, bson.M{"_id": w.collectionName}).Decode(&stored)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return stored.Token, nil
+}
+
+func (w *Watcher) saveToken(ctx context.Context, token bson.Raw) error {
+	stored := resumeToken{ID: w.collectionName, Token: token, UpdatedAt: time.Now()}
+	_, err := w.db.Collection(w.tokenCollection).ReplaceOne(ctx, bson.M{"_id": w.collectionName}, stored, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (w *Watcher) deleteToken(ctx context.Context) error {
+	_, err := w.db.Collection(w.tokenCollection).DeleteOne(ctx, bson.M{"_id": w.collectionName})
+	return err
+}
+
+func isHistoryLost(err error) bool {
+	var serverErr mongo.ServerError
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(ERROR_CHANGE_STREAM_HISTORY_LOST)
+}